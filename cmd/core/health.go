@@ -0,0 +1,80 @@
+package main
+
+import (
+	"GradingCore2/pkg/grading"
+	"GradingCore2/pkg/runner"
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+)
+
+// templateHealth is the outcome of the startup check of a template
+type templateHealth struct {
+	Id       string
+	Image    string
+	Pinned   string
+	SelfTest string
+	Err      error
+}
+
+// CheckTemplates prepares the image of every template and runs its self test, the failing templates are
+// disabled and the healthy ones are returned
+func CheckTemplates(runnerService *runner.Service, gradingService *grading.Service, templateMap grading.TemplateMap, selfTestTimeout time.Duration) []*runner.ContainerTemplate {
+	ids := make([]string, 0, len(templateMap))
+	for id := range templateMap {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	healthy := make([]*runner.ContainerTemplate, 0, len(ids))
+	report := make([]templateHealth, 0, len(ids))
+	for _, id := range ids {
+		template := templateMap[id]
+		health := checkTemplate(runnerService, gradingService, template, selfTestTimeout)
+		report = append(report, health)
+
+		if health.Err != nil {
+			gradingService.DisableTemplate(id, health.Err)
+			continue
+		}
+		healthy = append(healthy, template)
+	}
+
+	log.Printf("template health: %d of %d healthy", len(healthy), len(report))
+	for _, health := range report {
+		if health.Err != nil {
+			log.Printf("  %-8s %-24s DISABLED %v", health.Id, health.Image, health.Err)
+		} else {
+			log.Printf("  %-8s %-24s ok %s self test %s", health.Id, health.Image, health.Pinned, health.SelfTest)
+		}
+	}
+	return healthy
+}
+
+func checkTemplate(runnerService *runner.Service, gradingService *grading.Service, template *runner.ContainerTemplate, selfTestTimeout time.Duration) templateHealth {
+	health := templateHealth{Id: template.Id, Image: template.Image}
+
+	// pulling an image may take much longer than a submission, it is not bounded
+	health.Err = runnerService.PrepareTemplate(context.Background(), template)
+	if health.Err != nil {
+		return health
+	}
+	health.Pinned = template.PinnedImage
+
+	ctx, cancel := context.WithTimeout(context.Background(), selfTestTimeout)
+	defer cancel()
+
+	err := gradingService.SelfTest(ctx, template)
+	switch {
+	case errors.Is(err, grading.ErrNoSelfTest):
+		health.SelfTest = "skipped"
+	case err != nil:
+		health.SelfTest = "failed"
+		health.Err = err
+	default:
+		health.SelfTest = "passed"
+	}
+	return health
+}
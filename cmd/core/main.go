@@ -8,12 +8,25 @@ import (
 	"GradingCore2/pkg/runner"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 )
 
+const (
+	DefaultOutputLimitHard = 1024
+	DefaultNativeDirectory = "/var/lib/gradingcore/sandbox"
+	DefaultNativeCgroup    = "/sys/fs/cgroup/gradingcore"
+	DefaultNativeUidBase   = 100000
+	DefaultMaxContainers   = 64
+)
+
+// DefaultSocketDirectory holds the sockets rin listens on when using the unix transport
+var DefaultSocketDirectory = filepath.Join(os.TempDir(), "gradingcore")
+
 type Configuration struct {
 	TemplateMap         grading.TemplateMap `json:"templates"`
 	AmqpUrl             string              `json:"amqp_url"`
@@ -22,7 +35,21 @@ type Configuration struct {
 	TimeLimitHardUser   int64               `json:"time_limit_hard_user"` // time in ms
 	TimeLimitHardSystem int64               `json:"time_limit_hard_system"`
 	MemoryLimitHard     int64               `json:"memory_limit_hard"` // memory limit in KiB
+	OutputLimitHard     int64               `json:"output_limit_hard"` // output limit in KiB
 	CpuLimitHard        float64             `json:"cpu_limit_hard"`    // CPU limit in core
+	ContainerLimit      int                 `json:"container_limit"`   // running containers allowed before submissions stop being sharded
+	MaxContainers       int                 `json:"max_containers"`    // containers which may exist at once including pools, whatever the concurrency
+	PinCpu              bool                `json:"pin_cpu"`           // dedicate a CPU to each container in place of sharing cpu_limit_hard
+	Cpus                string              `json:"cpus"`              // CPUs available for pinning such as 2-7, every CPU if empty
+	Runner              string              `json:"runner"`            // docker, podman or native
+	PodmanSocket        string              `json:"podman_socket"`     // socket of the Podman service, the rootless one by default
+	Transport           string              `json:"transport"`         // unix or tcp, how the core reaches rin in Docker and Podman containers, Podman only supports unix
+	SocketDirectory     string              `json:"socket_directory"`  // host directory of the sockets of the unix transport
+	NativeDirectory     string              `json:"native_directory"`  // where the native runner mounts its sandboxes
+	NativeCgroup        string              `json:"native_cgroup"`     // cgroup v2 directory the native sandboxes are created in
+	NativeUidBase       int                 `json:"native_uid_base"`   // first host uid mapped into the native sandboxes
+
+	PinnedCpus []int `json:"-"` // parsed from cpus when pin_cpu is set
 }
 
 func LoadConfig() (*Configuration, error) {
@@ -45,16 +72,117 @@ func LoadConfig() (*Configuration, error) {
 		return nil, fmt.Errorf("invalid memory hard limit: %d", config.MemoryLimitHard)
 	}
 
+	if config.OutputLimitHard <= 0 {
+		config.OutputLimitHard = DefaultOutputLimitHard
+	}
+
+	switch config.Runner {
+	case "":
+		config.Runner = "docker"
+	case "docker", "podman", "native":
+	default:
+		return nil, fmt.Errorf("unknown runner: %s", config.Runner)
+	}
+
+	switch config.Transport {
+	case "":
+		config.Transport = "unix"
+	case "unix", "tcp":
+	default:
+		return nil, fmt.Errorf("unknown transport: %s", config.Transport)
+	}
+
+	// rootless Podman keeps the container addresses in a network namespace the host cannot reach, and
+	// cannot publish ports on the internal network rin is otherwise reached through
+	if config.Runner == "podman" && config.Transport == "tcp" {
+		return nil, errors.New("the podman runner only supports the unix transport")
+	}
+
+	if config.SocketDirectory == "" {
+		config.SocketDirectory = DefaultSocketDirectory
+	}
+
+	if config.PodmanSocket == "" {
+		config.PodmanSocket = runner.DefaultPodmanSocket()
+	}
+
+	if config.NativeDirectory == "" {
+		config.NativeDirectory = DefaultNativeDirectory
+	}
+
+	if config.NativeCgroup == "" {
+		config.NativeCgroup = DefaultNativeCgroup
+	}
+
+	if config.NativeUidBase <= 0 {
+		config.NativeUidBase = DefaultNativeUidBase
+	}
+
+	if config.ContainerLimit < config.Concurrency {
+		config.ContainerLimit = config.Concurrency
+	}
+
+	if config.MaxContainers <= 0 {
+		config.MaxContainers = DefaultMaxContainers
+	}
+
+	if config.PinCpu {
+		cpus, err := runner.ParseCpuList(config.Cpus)
+		if err != nil {
+			return nil, err
+		}
+		config.PinnedCpus = cpus
+
+		// every submission being graded needs a CPU of its own, extra shards only get the CPUs left over
+		if config.Concurrency > len(cpus) {
+			return nil, fmt.Errorf("concurrency %d exceeds the %d CPUs available for pinning", config.Concurrency, len(cpus))
+		}
+		if config.MaxContainers > len(cpus) {
+			config.MaxContainers = len(cpus)
+		}
+	}
+
+	if config.Concurrency > config.MaxContainers {
+		return nil, fmt.Errorf("concurrency %d exceeds the maximum of %d containers", config.Concurrency, config.MaxContainers)
+	}
+	if config.ContainerLimit > config.MaxContainers {
+		config.ContainerLimit = config.MaxContainers
+	}
+
 	return &config, nil
 }
 
+func NewRunner(config *Configuration) (runner.Runner, error) {
+	socketDirectory := ""
+	if config.Transport == "unix" {
+		socketDirectory = config.SocketDirectory
+	}
+
+	switch config.Runner {
+	case "podman":
+		return runner.NewPodmanRunner(config.CpuLimitHard, config.MemoryLimitHard, config.PodmanSocket, socketDirectory)
+	case "native":
+		return runner.NewNativeRunner(config.CpuLimitHard, config.MemoryLimitHard, config.NativeDirectory, config.NativeCgroup, config.NativeUidBase)
+	default:
+		return runner.NewDockerRunner(config.CpuLimitHard, config.MemoryLimitHard, socketDirectory)
+	}
+}
+
 func main() {
 	config, err := LoadConfig()
 	if err != nil {
 		panic(err)
 	}
 
-	runnerService, err := runner.NewService(config.CpuLimitHard, config.MemoryLimitHard)
+	containerRunner, err := NewRunner(config)
+	if err != nil {
+		panic(err)
+	}
+	if len(config.PinnedCpus) > 0 && !containerRunner.PinsCpus() {
+		log.Println("the runner cannot pin containers to CPUs, they share cpu_limit_hard instead")
+	}
+
+	runnerService, err := runner.NewService(containerRunner, config.MaxContainers, config.ContainerLimit, config.PinnedCpus, time.Duration(config.TimeLimitHardSystem)*time.Millisecond)
 	if err != nil {
 		panic(err)
 	}
@@ -71,11 +199,21 @@ func main() {
 		}
 	}(runnerService, context.Background())
 
-	gradingService, err := grading.NewService(runnerService, config.TemplateMap, config.TimeLimitHardUser, config.TimeLimitHardSystem, config.MemoryLimitHard)
+	gradingService, err := grading.NewService(runnerService, config.TemplateMap, config.TimeLimitHardUser, config.TimeLimitHardSystem, config.MemoryLimitHard, config.OutputLimitHard)
 	if err != nil {
 		panic(err)
 	}
 
+	// a missing image or a broken toolchain is found now rather than by the first submission of the language
+	healthy := CheckTemplates(runnerService, gradingService, config.TemplateMap, gradingService.TimeLimitHardSystem)
+	if len(healthy) == 0 {
+		log.Println("no template is healthy, every submission will fail")
+	}
+
+	for _, template := range healthy {
+		runnerService.RegisterTemplate(template)
+	}
+
 	gatewayService := gateway.NewService(config.AmqpUrl, config.Concurrency, gradingService)
 	go func() {
 		for gatewayService.Running {
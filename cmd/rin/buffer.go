@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"sync"
+)
+
+const TruncatedMarker = "\n... (output truncated)\n"
+
+// LimitedBuffer keeps at most Limit bytes of what is written to it and silently discards the rest,
+// OnExceed is called once when the limit is first exceeded
+type LimitedBuffer struct {
+	buffer   bytes.Buffer
+	Limit    int64
+	Exceeded bool
+	OnExceed func()
+	lock     sync.Mutex
+}
+
+func (b *LimitedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	remaining := b.Limit - int64(b.buffer.Len())
+	if b.Limit <= 0 || int64(len(p)) <= remaining {
+		return b.buffer.Write(p)
+	}
+
+	if remaining > 0 {
+		b.buffer.Write(p[:remaining])
+	}
+	if !b.Exceeded {
+		b.Exceeded = true
+		if b.OnExceed != nil {
+			b.OnExceed()
+		}
+	}
+	return len(p), nil
+}
+
+// Bytes never returns nil, required proto fields treat a nil slice as unset
+func (b *LimitedBuffer) Bytes() []byte {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	data := b.buffer.Bytes()
+	if data == nil {
+		return []byte{}
+	}
+	return data
+}
+
+// Truncated returns the content with a marker appended if anything was discarded
+func (b *LimitedBuffer) Truncated() []byte {
+	data := b.Bytes()
+	if !b.Exceeded {
+		return data
+	}
+	return append(data[:len(data):len(data)], TruncatedMarker...)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"GradingCore2/pkg/platform"
+	"log"
+	"net"
+)
+
+// SandboxGuard drops connections coming from inside the sandbox, a submitted program talking to rin
+// could replace the checker or forge the results of its own tests
+type SandboxGuard struct {
+	net.Listener
+	SandboxUid int // -1 if submitted programs run as rin itself and cannot be told apart on a unix socket
+}
+
+func (g *SandboxGuard) Accept() (net.Conn, error) {
+	for {
+		conn, err := g.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if !g.fromSandbox(conn) {
+			return conn, nil
+		}
+
+		log.Println("rejected connection from the sandbox", conn.RemoteAddr())
+		err = conn.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// fromSandbox tells by the user of the peer on a unix socket, the core never connects over TCP from
+// within the network namespace of the sandbox so a local peer address is a submitted program
+func (g *SandboxGuard) fromSandbox(conn net.Conn) bool {
+	switch conn := conn.(type) {
+	case *net.UnixConn:
+		if g.SandboxUid < 0 {
+			return false
+		}
+		uid, err := platform.PeerUid(conn)
+		if err != nil {
+			log.Println("failed to identify peer", err)
+			return true
+		}
+		return uid == g.SandboxUid
+	case *net.TCPConn:
+		local, localOk := conn.LocalAddr().(*net.TCPAddr)
+		remote, remoteOk := conn.RemoteAddr().(*net.TCPAddr)
+		if !localOk || !remoteOk {
+			return true
+		}
+		return remote.IP.IsLoopback() || remote.IP.Equal(local.IP)
+	}
+	return false
+}
@@ -4,26 +4,64 @@ import (
 	"GradingCore2/pkg/platform"
 	"GradingCore2/pkg/protorin"
 	"GradingCore2/pkg/scrubber"
-	"bytes"
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"google.golang.org/grpc"
 	"log"
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	CheckerInputPath  = "checker_input.txt"
+	CheckerOutputPath = "checker_output.txt"
+	CheckerAnswerPath = "checker_answer.txt"
+
+	InteractorOutputPath = "interactor_output.txt"
+
+	DefaultOutputLimit        = 2 << 20 // stays well within the default gRPC message size limit
+	DefaultCompileOutputLimit = 64 << 10
+	StderrLimit               = 4 << 10 // only an excerpt of stderr is kept for diagnostics
+	CheckerOutputLimit        = 64 << 10
+
+	CheckTimeLimit        = 10 * time.Second // applies even when the core sets no deadline
+	DefaultJudgeDirectory = "/judge"
+	SharedMemoryDirectory = "/dev/shm"
+
+	OrphanWaitDelay = 100 * time.Millisecond
+)
+
 type Handler struct {
 	protorin.RinServer
-	SourcePath     string
-	TestInputPath  string
-	CompileCommand []string
-	TestCommand    []string
-	Server         *grpc.Server
+	SourcePath               string
+	TestInputPath            string
+	CheckerSourcePath        string
+	InteractorSourcePath     string
+	CompileCommand           []string
+	TestCommand              []string
+	CheckerCompileCommand    []string
+	CheckerCommand           []string
+	InteractorCompileCommand []string
+	InteractorCommand        []string
+	CompileOutputLimit       int64
+	JudgeDirectory           string // only accessible to rin, holds the checker and the interactor
+	SeedDirectory            string // files copied into the work directory at start and on reset, such as a go.mod
+	SandboxUid               int    // user submitted programs and their compiler run as, -1 to run them as rin
+	SandboxGid               int
+	Cgroup                   *platform.Cgroup           // delegated cgroup for per-test limits, nil if unavailable
+	Baseline                 map[string]map[string]bool // entries of each directory wiped by Reset, as they were at start
+	Server                   *grpc.Server
+	// tests share the judge input and the test cgroup, the handler of a cancelled batch may still be running one
+	// when the next batch arrives
+	testLock sync.Mutex
 }
 
 func (h *Handler) Ping(_ context.Context, _ *protorin.Empty) (*protorin.Empty, error) {
@@ -31,22 +69,38 @@ func (h *Handler) Ping(_ context.Context, _ *protorin.Empty) (*protorin.Empty, e
 }
 
 func (h *Handler) Compile(_ context.Context, src *protorin.Source) (*protorin.CompileResult, error) {
-	file, err := os.Create(h.SourcePath)
-	if err != nil {
-		return nil, err
-	}
+	return h.compile(".", h.SourcePath, h.CompileCommand, src.Source, true)
+}
+
+func (h *Handler) CompileChecker(_ context.Context, src *protorin.Source) (*protorin.CompileResult, error) {
+	return h.compile(h.JudgeDirectory, h.CheckerSourcePath, h.CheckerCompileCommand, src.Source, false)
+}
+
+func (h *Handler) CompileInteractor(_ context.Context, src *protorin.Source) (*protorin.CompileResult, error) {
+	return h.compile(h.JudgeDirectory, h.InteractorSourcePath, h.InteractorCompileCommand, src.Source, false)
+}
 
-	_, err = file.Write(src.Source)
+// compile builds the source in the directory, the compiler runs in the sandbox for submitted code
+// so that nothing it produces is trusted, and as rin for the judge programs
+func (h *Handler) compile(directory string, sourcePath string, compileCommand []string, source []byte, sandboxed bool) (*protorin.CompileResult, error) {
+	err := os.WriteFile(filepath.Join(directory, sourcePath), source, 0644)
 	if err != nil {
 		return nil, err
 	}
 
-	command := exec.Command(h.CompileCommand[0], h.CompileCommand[1:]...)
-	buffer := bytes.Buffer{}
+	command := exec.Command(compileCommand[0], compileCommand[1:]...)
+	command.Dir = directory
+	if sandboxed {
+		err = h.sandbox(command)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buffer := LimitedBuffer{Limit: h.CompileOutputLimit}
 	command.Stdout = &buffer
 	command.Stderr = &buffer
 	err = command.Run()
-	dataBytes := buffer.Bytes()
+	dataBytes := buffer.Truncated()
 
 	success := err == nil
 	result := protorin.CompileResult{Data: dataBytes, Success: &success}
@@ -55,61 +109,547 @@ func (h *Handler) Compile(_ context.Context, src *protorin.Source) (*protorin.Co
 	return &result, nil
 }
 
-func (h *Handler) Test(_ context.Context, src *protorin.TestContext) (*protorin.TestResult, error) {
-	file, err := os.Create(h.TestInputPath)
-	if err != nil {
-		return nil, err
+func (h *Handler) Test(ctx context.Context, src *protorin.TestContext) (*protorin.TestResult, error) {
+	h.testLock.Lock()
+	defer h.testLock.Unlock()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
 
-	_, err = file.Write(src.Source)
+	// the input is kept from submitted programs, they only get it on their standard input
+	err := os.WriteFile(filepath.Join(h.JudgeDirectory, h.TestInputPath), src.Source, 0644)
 	if err != nil {
 		return nil, err
 	}
 
-	testFile, err := os.Open("test.txt")
-	if err != nil {
-		return nil, err
+	limits := platform.ProcessLimits{
+		CpuTime: time.Duration(src.GetTimeLimitCpu()) * time.Millisecond,
 	}
-	defer func(testFile *os.File) {
-		err := testFile.Close()
+	outputLimit := src.GetOutputLimit()
+	if outputLimit <= 0 {
+		outputLimit = DefaultOutputLimit
+	}
+	memoryLimit := src.GetMemoryLimit()
+	if memoryLimit > 0 && h.Cgroup != nil {
+		testCgroup, err := h.createTestCgroup(memoryLimit)
 		if err != nil {
-			log.Println(err)
+			return nil, err
 		}
-	}(testFile)
+		defer func() {
+			err := testCgroup.Destroy()
+			if err != nil {
+				log.Println("failed to destroy test cgroup", err)
+			}
+		}()
+		limits.Cgroup = testCgroup
+	} else {
+		limits.Memory = memoryLimit
+	}
 
-	command := exec.Command(h.TestCommand[0], h.TestCommand[1:]...)
-	buffer := bytes.Buffer{}
-	command.Stdin = testFile
-	command.Stdout = &buffer
-	command.Stderr = &buffer
-	err = command.Run()
+	timeLimitWall := time.Duration(src.GetTimeLimitWall()) * time.Millisecond
+	if timeLimitWall > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeLimitWall)
+		defer cancel()
+	}
 
-	usage, reportErr := platform.ReportUsage()
+	result := protorin.TestResult{}
+	var command *exec.Cmd
+	var dataBytes []byte
+	timeStart := time.Now()
+	if src.GetOptInteractive() {
+		command, dataBytes, err = h.runInteractive(ctx, &limits, &result)
+	} else {
+		command, dataBytes, err = h.run(ctx, &limits, outputLimit, &result)
+	}
+	timeWall := time.Now().Sub(timeStart).Microseconds()
+	if err != nil && (command == nil || command.ProcessState == nil) {
+		return nil, err
+	}
+	result.TimeWall = &timeWall
+
+	usage, reportErr := platform.ReportUsage(command.ProcessState)
 	if reportErr != nil {
-		log.Println(usage)
+		log.Println(reportErr)
 	}
 
-	dataBytes := buffer.Bytes()
 	dataBytes = scrubber.Scrub(dataBytes)
 	hashAlgo := sha256.New()
 	hashAlgo.Write(dataBytes)
-	hashBytes := hashAlgo.Sum(nil)
+	result.Hash = hashAlgo.Sum(nil)
 
-	result := protorin.TestResult{Hash: hashBytes}
 	if !src.GetOptHashOnly() {
 		result.Result = dataBytes
 	}
 
+	exitCode, signal := platform.ReportExit(command.ProcessState)
+	result.ExitCode = &exitCode
+	result.Signal = &signal
+	if signal != 0 {
+		signalName := platform.SignalName(signal)
+		result.SignalName = &signalName
+	}
+
+	timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
 	if usage != nil {
 		result.Memory = &usage.MaxResidentSize
 		result.TimeUser = &usage.TimeUser
 		result.TimeSystem = &usage.TimeSystem
+
+		timeCpu := time.Duration(usage.TimeUser+usage.TimeSystem) * time.Microsecond
+		timedOut = timedOut || (limits.CpuTime > 0 && timeCpu > limits.CpuTime)
 	}
+	result.TimedOut = &timedOut
+
+	// under RLIMIT_DATA a failed allocation cannot be told apart from any other crash, it is reported as such
+	memoryExceeded := memoryLimit > 0 && result.GetMemory() > memoryLimit
+	if limits.Cgroup != nil {
+		oomKilled, err := limits.Cgroup.OomKilled()
+		if err != nil {
+			log.Println("failed to read OOM events", err)
+		}
+		memoryExceeded = memoryExceeded || oomKilled
+	}
+	result.MemoryExceeded = &memoryExceeded
+
+	killReason := protorin.KillReason_KILL_REASON_NONE
+	switch {
+	case result.GetOutputLimitExceeded():
+		killReason = protorin.KillReason_KILL_REASON_OUTPUT_LIMIT
+	case timedOut:
+		killReason = protorin.KillReason_KILL_REASON_TIME_LIMIT
+	case memoryExceeded:
+		killReason = protorin.KillReason_KILL_REASON_MEMORY_LIMIT
+	}
+	result.KillReason = &killReason
 
 	log.Println(string(dataBytes), err)
 	return &result, nil
 }
 
+func (h *Handler) createTestCgroup(memoryLimit int64) (*platform.Cgroup, error) {
+	testCgroup, err := h.Cgroup.Child("test")
+	if err != nil {
+		return nil, err
+	}
+
+	err = testCgroup.SetMemoryLimit(memoryLimit)
+	if err != nil {
+		destroyErr := testCgroup.Destroy()
+		if destroyErr != nil {
+			log.Println(destroyErr)
+		}
+		return nil, err
+	}
+	return testCgroup, nil
+}
+
+func (h *Handler) TestBatch(src *protorin.TestBatchContext, stream protorin.Rin_TestBatchServer) error {
+	for index, testContext := range src.Cases {
+		result, err := h.Test(stream.Context(), testContext)
+		if err != nil {
+			return err
+		}
+
+		batchIndex := int32(index)
+		err = stream.Send(&protorin.TestBatchResult{Index: &batchIndex, Result: result})
+		if err != nil {
+			return err
+		}
+
+		if src.GetOptStopOnFailure() && failed(result) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// failed reports whether the test has failed regardless of its output
+func failed(result *protorin.TestResult) bool {
+	return result.GetKillReason() != protorin.KillReason_KILL_REASON_NONE ||
+		result.GetExitCode() != 0 ||
+		result.GetSignal() != 0 ||
+		(result.InteractorExitCode != nil && result.GetInteractorExitCode() != 0)
+}
+
+// newCommand creates a command in its own process group, the whole group is killed once the context is done
+func newCommand(ctx context.Context, commandLine []string, args ...string) *exec.Cmd {
+	commandArgs := make([]string, 0, len(commandLine)+len(args))
+	commandArgs = append(commandArgs, commandLine[1:]...)
+	commandArgs = append(commandArgs, args...)
+
+	command := exec.CommandContext(ctx, commandLine[0], commandArgs...)
+	platform.PrepareCommand(command)
+	command.Cancel = func() error {
+		return platform.KillProcessGroup(command.Process)
+	}
+	// a detached descendant keeping the output pipes open must not hold up the command, Reset kills it later
+	command.WaitDelay = OrphanWaitDelay
+	return command
+}
+
+// sandbox makes the command run as the sandbox user, which can neither write the judge programs nor
+// trace rin and the judge programs
+func (h *Handler) sandbox(command *exec.Cmd) error {
+	if h.SandboxUid < 0 {
+		return nil
+	}
+	return platform.RunAs(command, h.SandboxUid, h.SandboxGid)
+}
+
+// startLimited starts the command and applies the resource limits to it
+func startLimited(command *exec.Cmd, limits *platform.ProcessLimits) error {
+	if limits.Cgroup != nil {
+		cgroupFile, err := limits.Cgroup.Attach(command)
+		if err != nil {
+			return err
+		}
+		defer closeFiles(cgroupFile)
+	}
+
+	err := command.Start()
+	if err != nil {
+		return err
+	}
+
+	err = platform.LimitProcess(command.Process.Pid, limits)
+	if err != nil {
+		killErr := platform.KillProcessGroup(command.Process)
+		if killErr != nil {
+			log.Println(killErr)
+		}
+		_ = command.Wait()
+		return err
+	}
+	return nil
+}
+
+func (h *Handler) run(ctx context.Context, limits *platform.ProcessLimits, outputLimit int64, result *protorin.TestResult) (*exec.Cmd, []byte, error) {
+	testFile, err := os.Open(filepath.Join(h.JudgeDirectory, h.TestInputPath))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func(testFile *os.File) {
+		err := testFile.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}(testFile)
+
+	// a process flooding its output is killed right away instead of running to its time limit
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	command := newCommand(ctx, h.TestCommand)
+	buffer := LimitedBuffer{Limit: outputLimit, OnExceed: cancel}
+	stderr := LimitedBuffer{Limit: StderrLimit}
+	command.Stdin = testFile
+	command.Stdout = &buffer
+	command.Stderr = &stderr
+	err = h.sandbox(command)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = startLimited(command, limits)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = command.Wait()
+
+	result.OutputLimitExceeded = &buffer.Exceeded
+	result.Stderr = stderr.Truncated()
+	return command, buffer.Bytes(), err
+}
+
+// runInteractive runs the contestant program alongside the interactor with their standard input and output
+// cross-connected, the interactor gets the test input and writes its output to a file which becomes the test output,
+// both files stay in the judge directory where the contestant program cannot reach them
+func (h *Handler) runInteractive(ctx context.Context, limits *platform.ProcessLimits, result *protorin.TestResult) (*exec.Cmd, []byte, error) {
+	// the output of a previous test would be taken for this one if the interactor does not write any
+	err := os.Remove(filepath.Join(h.JudgeDirectory, InteractorOutputPath))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, nil, err
+	}
+
+	contestantReader, interactorWriter, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	interactorReader, contestantWriter, err := os.Pipe()
+	if err != nil {
+		closeFiles(contestantReader, interactorWriter)
+		return nil, nil, err
+	}
+
+	interactor := newCommand(ctx, h.InteractorCommand, h.TestInputPath, InteractorOutputPath)
+	interactor.Dir = h.JudgeDirectory
+	interactorMessage := LimitedBuffer{Limit: StderrLimit}
+	interactor.Stdin = interactorReader
+	interactor.Stdout = interactorWriter
+	interactor.Stderr = &interactorMessage
+
+	command := newCommand(ctx, h.TestCommand)
+	command.Stdin = contestantReader
+	command.Stdout = contestantWriter
+	stderr := LimitedBuffer{Limit: StderrLimit}
+	command.Stderr = &stderr
+	err = h.sandbox(command)
+	if err != nil {
+		closeFiles(contestantReader, interactorWriter, interactorReader, contestantWriter)
+		return nil, nil, err
+	}
+
+	err = interactor.Start()
+	if err != nil {
+		closeFiles(contestantReader, interactorWriter, interactorReader, contestantWriter)
+		return nil, nil, err
+	}
+
+	err = startLimited(command, limits)
+	// the children hold their own copies, closing ours lets either side observe EOF once the other exits
+	closeFiles(contestantReader, interactorWriter, interactorReader, contestantWriter)
+	if err != nil {
+		killErr := platform.KillProcessGroup(interactor.Process)
+		if killErr != nil {
+			log.Println(killErr)
+		}
+		_ = interactor.Wait()
+		return nil, nil, err
+	}
+
+	err = command.Wait()
+	interactorErr := interactor.Wait()
+	if interactorErr != nil && interactor.ProcessState == nil {
+		return nil, nil, interactorErr
+	}
+
+	interactorExitCode := int32(interactor.ProcessState.ExitCode())
+	result.InteractorExitCode = &interactorExitCode
+	result.InteractorMessage = interactorMessage.Truncated()
+	result.Stderr = stderr.Truncated()
+	log.Println("interactor", interactorExitCode, string(result.InteractorMessage))
+
+	dataBytes, readErr := os.ReadFile(filepath.Join(h.JudgeDirectory, InteractorOutputPath))
+	if readErr != nil && !os.IsNotExist(readErr) {
+		return nil, nil, readErr
+	}
+
+	return command, dataBytes, err
+}
+
+func closeFiles(files ...*os.File) {
+	for _, file := range files {
+		err := file.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (h *Handler) Check(ctx context.Context, src *protorin.CheckContext) (*protorin.CheckResult, error) {
+	files := map[string][]byte{
+		CheckerInputPath:  src.Input,
+		CheckerOutputPath: src.Output,
+		CheckerAnswerPath: src.Expected,
+	}
+	for path, data := range files {
+		err := os.WriteFile(filepath.Join(h.JudgeDirectory, path), data, 0644)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, CheckTimeLimit)
+	defer cancel()
+
+	// testlib argument order: input, contestant output, expected answer
+	command := newCommand(ctx, h.CheckerCommand, CheckerInputPath, CheckerOutputPath, CheckerAnswerPath)
+	command.Dir = h.JudgeDirectory
+	buffer := LimitedBuffer{Limit: CheckerOutputLimit}
+	command.Stdout = &buffer
+	command.Stderr = &buffer
+	err := command.Run()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("checker did not finish: %w", ctx.Err())
+	}
+
+	var exitCode int32
+	if err != nil {
+		exitError, ok := err.(*exec.ExitError)
+		if !ok {
+			return nil, err
+		}
+		exitCode = int32(exitError.ExitCode())
+	}
+
+	message := buffer.Truncated()
+	log.Println("checker", exitCode, string(message))
+	return &protorin.CheckResult{ExitCode: &exitCode, Message: message}, nil
+}
+
+// Reset brings the sandbox back to the state it had when rin started so that the container can serve
+// another submission, it kills leftover processes, wipes the directories and then verifies both
+func (h *Handler) Reset(_ context.Context, _ *protorin.Empty) (*protorin.ResetResult, error) {
+	problems := make([]string, 0)
+
+	err := platform.KillStrayProcesses()
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("failed to kill leftover processes: %v", err))
+	}
+
+	for directory, baseline := range h.Baseline {
+		extra, err := extraEntries(directory, baseline)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("failed to list %s: %v", directory, err))
+			continue
+		}
+
+		for _, name := range extra {
+			err := os.RemoveAll(filepath.Join(directory, name))
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("failed to remove %s: %v", name, err))
+			}
+		}
+	}
+
+	err = h.seed()
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("failed to seed the work directory: %v", err))
+	}
+
+	// killed processes may take a moment to disappear
+	stray, err := platform.StrayProcesses()
+	for retry := 0; err == nil && len(stray) > 0 && retry < 10; retry++ {
+		time.Sleep(50 * time.Millisecond)
+		stray, err = platform.StrayProcesses()
+	}
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("failed to list processes: %v", err))
+	} else if len(stray) > 0 {
+		problems = append(problems, fmt.Sprintf("processes still running: %v", stray))
+	}
+
+	for directory, baseline := range h.Baseline {
+		extra, err := extraEntries(directory, baseline)
+		if err == nil && len(extra) > 0 {
+			problems = append(problems, fmt.Sprintf("%s still contains %v", directory, extra))
+		}
+	}
+
+	clean := len(problems) == 0
+	if !clean {
+		log.Println("reset failed", problems)
+	}
+	return &protorin.ResetResult{Clean: &clean, Message: []byte(strings.Join(problems, "\n"))}, nil
+}
+
+// seed copies the files of the seed directory into the work directory, they belong to the sandbox user
+// as compiling may update them, such as the go.mod of a submission importing modules
+func (h *Handler) seed() error {
+	if h.SeedDirectory == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(h.SeedDirectory)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(h.SeedDirectory, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		// the previous file may have been replaced by a link pointing anywhere, it is never written through
+		err = os.Remove(entry.Name())
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		file, err := os.OpenFile(entry.Name(), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = file.Write(data)
+		if err == nil && h.SandboxUid >= 0 {
+			err = file.Chown(h.SandboxUid, h.SandboxGid)
+		}
+		closeFiles(file)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordBaseline records the current entries of the directory for Reset, a missing directory is skipped
+func (h *Handler) recordBaseline(directory string) error {
+	entries, err := os.ReadDir(directory)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	baseline := make(map[string]bool)
+	for _, entry := range entries {
+		baseline[entry.Name()] = true
+	}
+	h.Baseline[directory] = baseline
+	return nil
+}
+
+// extraEntries lists the entries of the directory which are not part of the baseline
+func extraEntries(directory string, baseline map[string]bool) ([]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	extra := make([]string, 0)
+	for _, entry := range entries {
+		if !baseline[entry.Name()] {
+			extra = append(extra, entry.Name())
+		}
+	}
+	return extra, nil
+}
+
+// Report describes the restrictions rin runs under, and with it every program it starts,
+// so that the core can verify the security profile of the container is in effect
+func (h *Handler) Report(_ context.Context, _ *protorin.Empty) (*protorin.SecurityReport, error) {
+	report, err := platform.ReportSecurity()
+	if err != nil {
+		return nil, err
+	}
+
+	uid := int32(report.Uid)
+	gid := int32(report.Gid)
+	seccompMode := int32(report.SeccompMode)
+	sandboxUid := int32(h.SandboxUid)
+	sandboxGid := int32(h.SandboxGid)
+	return &protorin.SecurityReport{
+		Uid:                   &uid,
+		Gid:                   &gid,
+		CapabilitiesEffective: &report.CapabilitiesEffective,
+		CapabilitiesBounding:  &report.CapabilitiesBounding,
+		NoNewPrivileges:       &report.NoNewPrivileges,
+		SeccompMode:           &seccompMode,
+		ReadOnlyRoot:          &report.ReadOnlyRoot,
+		FileLimit:             &report.FileLimit,
+		FileSizeLimit:         &report.FileSizeLimit,
+		PidsLimit:             &report.PidsLimit,
+		SandboxUid:            &sandboxUid,
+		SandboxGid:            &sandboxGid,
+	}, nil
+}
+
 func (h *Handler) Shutdown(context.Context, *protorin.Empty) (*protorin.Empty, error) {
 	go func() {
 		time.Sleep(1 * time.Second)
@@ -118,24 +658,154 @@ func (h *Handler) Shutdown(context.Context, *protorin.Empty) (*protorin.Empty, e
 	return &protorin.Empty{}, nil
 }
 
+// listenOn listens on a TCP address, or on a unix socket for addresses such as unix:///run/rin.sock
+func listenOn(address string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, "unix://")
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+
+	err := os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// the core may run as another user, access is restricted by the directory holding the socket
+	err = os.Chmod(path, 0666)
+	if err != nil {
+		closeErr := listener.Close()
+		if closeErr != nil {
+			log.Println(closeErr)
+		}
+		return nil, err
+	}
+	return listener, nil
+}
+
+// parseUser parses a numeric uid:gid
+func parseUser(user string) (int, int, error) {
+	uidText, gidText, ok := strings.Cut(user, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("user %s is not uid:gid", user)
+	}
+
+	uid, err := strconv.Atoi(uidText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid uid in %s %w", user, err)
+	}
+	gid, err := strconv.Atoi(gidText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid gid in %s %w", user, err)
+	}
+	return uid, gid, nil
+}
+
 func main() {
 	handler := Handler{
-		SourcePath:     os.Getenv("RIN_SOURCE"),
-		TestInputPath:  os.Getenv("RIN_TEST_INPUT"),
-		CompileCommand: strings.Split(os.Getenv("RIN_CMD_COMPILE"), " "),
-		TestCommand:    strings.Split(os.Getenv("RIN_CMD_TEST"), " "),
+		SourcePath:               os.Getenv("RIN_SOURCE"),
+		TestInputPath:            os.Getenv("RIN_TEST_INPUT"),
+		CheckerSourcePath:        os.Getenv("RIN_CHECKER_SOURCE"),
+		InteractorSourcePath:     os.Getenv("RIN_INTERACTOR_SOURCE"),
+		CompileCommand:           strings.Split(os.Getenv("RIN_CMD_COMPILE"), " "),
+		TestCommand:              strings.Split(os.Getenv("RIN_CMD_TEST"), " "),
+		CheckerCompileCommand:    strings.Split(os.Getenv("RIN_CMD_CHECKER_COMPILE"), " "),
+		CheckerCommand:           strings.Split(os.Getenv("RIN_CMD_CHECKER"), " "),
+		InteractorCompileCommand: strings.Split(os.Getenv("RIN_CMD_INTERACTOR_COMPILE"), " "),
+		InteractorCommand:        strings.Split(os.Getenv("RIN_CMD_INTERACTOR"), " "),
 	}
 	listenAddress := os.Getenv("RIN_LISTEN")
 
-	listen, err := net.Listen("tcp", listenAddress)
+	handler.CompileOutputLimit = DefaultCompileOutputLimit
+	compileOutputLimit := os.Getenv("RIN_COMPILE_OUTPUT_LIMIT")
+	if compileOutputLimit != "" {
+		limit, err := strconv.ParseInt(compileOutputLimit, 10, 64)
+		if err != nil {
+			panic(err)
+		}
+		handler.CompileOutputLimit = limit
+	}
+
+	// the user is given as uid:gid, without it submitted programs run as rin itself
+	handler.SandboxUid, handler.SandboxGid = -1, -1
+	sandboxUser := os.Getenv("RIN_SANDBOX_USER")
+	if sandboxUser != "" {
+		uid, gid, err := parseUser(sandboxUser)
+		if err != nil {
+			panic(err)
+		}
+		handler.SandboxUid, handler.SandboxGid = uid, gid
+	}
+
+	handler.JudgeDirectory = os.Getenv("RIN_JUDGE_DIR")
+	if handler.JudgeDirectory == "" {
+		handler.JudgeDirectory = DefaultJudgeDirectory
+	}
+	err := os.MkdirAll(handler.JudgeDirectory, 0700)
+	if err == nil {
+		err = os.Chmod(handler.JudgeDirectory, 0700)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	handler.SeedDirectory = os.Getenv("RIN_SEED")
+	err = handler.seed()
+	if err != nil {
+		panic(err)
+	}
+
+	// only given by deployments which delegate a cgroup to rin, otherwise memory is limited by RLIMIT_DATA
+	cgroupPath := os.Getenv("RIN_CGROUP")
+	if cgroupPath != "" {
+		cgroup, err := platform.SetupCgroup(cgroupPath)
+		if err != nil {
+			log.Println("cgroup unavailable, falling back to rlimit", err)
+		} else {
+			handler.Cgroup = cgroup
+		}
+	}
+
+	// the work directory, the temporary directory, the judge directory and the shared memory are wiped back
+	// to their current content on reset
+	handler.Baseline = make(map[string]map[string]bool)
+	for _, directory := range []string{".", os.TempDir(), handler.JudgeDirectory, SharedMemoryDirectory} {
+		err := handler.recordBaseline(directory)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// inside a fresh pid namespace rin is init and needs its own procfs to find leftover processes
+	if os.Getenv("RIN_MOUNT_PROC") != "" {
+		err := platform.MountProc()
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	listen, err := listenOn(listenAddress)
 	if err != nil {
 		panic(err)
 	}
+
+	// so is the directory of the socket, once the socket is in it
+	socketPath, ok := strings.CutPrefix(listenAddress, "unix://")
+	if ok {
+		err = handler.recordBaseline(filepath.Dir(socketPath))
+		if err != nil {
+			panic(err)
+		}
+	}
 	fmt.Println("listening on", listenAddress)
 	server := grpc.NewServer()
 	handler.Server = server
 	protorin.RegisterRinServer(server, &handler)
-	err = server.Serve(listen)
+	err = server.Serve(&SandboxGuard{Listener: listen, SandboxUid: handler.SandboxUid})
 	if err != nil {
 		panic(err)
 	}
@@ -18,6 +18,6 @@ func main() {
 	}
 	log.Println(buf.Bytes())
 
-	result, err := platform.ReportUsage()
+	result, err := platform.ReportUsage(command.ProcessState)
 	log.Printf("%+v %+v\n", result, err)
 }
@@ -0,0 +1,5 @@
+#!/bin/sh
+# resolves the modules imported by the submission, which needs the compile network, and builds it
+set -e
+go mod tidy
+go build -o main
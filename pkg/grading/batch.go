@@ -0,0 +1,330 @@
+package grading
+
+import (
+	"GradingCore2/pkg/protorin"
+	"GradingCore2/pkg/runner"
+	"context"
+	"encoding/base64"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// BatchSizeLimit caps the total size of the inputs sent in a single TestBatch call,
+// keeping it under the default gRPC message size limit
+const BatchSizeLimit = 2 << 20
+
+// caseLimits are the limits applied to every test case of a request
+type caseLimits struct {
+	TimeSoft   time.Duration
+	TimeHard   time.Duration
+	MemorySoft int64 // in KiB
+	Output     int64 // in bytes
+}
+
+// gradingJob holds the state of running the test cases of a single request, the cases may be
+// sharded across several containers which share the results and the group state
+type gradingJob struct {
+	Service     *Service
+	Request     *Request
+	Response    *Response
+	Limits      caseLimits
+	Checkers    []Checker
+	Tracker     *groupTracker
+	Interactive bool
+	stopped     bool
+	lock        sync.Mutex // guards Tracker and stopped
+}
+
+// gradingShard is a container running a subset of the cases of a job
+type gradingShard struct {
+	Client    protorin.RinClient
+	Container *runner.ContainerInfo
+	Judge     Checker // special judge compiled in this container, overrides the checkers of the job
+	Cases     []int   // indices into Request.TestCase, in ascending order
+}
+
+// skip reports whether the case does not need to run anymore
+func (j *gradingJob) skip(index int) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.stopped || j.Tracker.ShouldSkip(index)
+}
+
+func (j *gradingJob) record(index int, result *ResultCase) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.Response.Result[index] = *result
+	j.Tracker.Record(index, result)
+	if !result.Pass && j.Request.Settings.StopOnFirstFailure {
+		j.stopped = true
+	}
+}
+
+func (j *gradingJob) stop() {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.stopped = true
+}
+
+// RunSharded runs every shard concurrently and returns the first failure, the results of the
+// cases are written in place so that they stay in the order of the request
+func (j *gradingJob) RunSharded(ctx context.Context, shards []*gradingShard) (StatusCode, error) {
+	shardContext, cancelShardContext := context.WithCancel(ctx)
+	defer cancelShardContext()
+
+	statusCodes := make([]StatusCode, len(shards))
+	errs := make([]error, len(shards))
+	wg := sync.WaitGroup{}
+	for i, shard := range shards {
+		wg.Add(1)
+		go func(i int, shard *gradingShard) {
+			defer wg.Done()
+			statusCodes[i], errs[i] = j.Run(shardContext, shard)
+			if errs[i] != nil || statusCodes[i] != "" {
+				cancelShardContext()
+			}
+		}(i, shard)
+	}
+	wg.Wait()
+
+	// shards cancelled because of another one report a context error, prefer the original failure
+	for i := range shards {
+		if statusCodes[i] != "" && !errors.Is(errs[i], context.Canceled) && status.Code(errs[i]) != codes.Canceled {
+			return statusCodes[i], errs[i]
+		}
+	}
+	for i := range shards {
+		if errs[i] != nil || statusCodes[i] != "" {
+			return statusCodes[i], errs[i]
+		}
+	}
+	return "", nil
+}
+
+// Run executes every case of the shard which is not skipped, in order, through as few TestBatch calls as possible
+func (j *gradingJob) Run(ctx context.Context, shard *gradingShard) (StatusCode, error) {
+	position := 0
+	// the input which did not fit in the previous batch, the next batch usually starts with it
+	carried := -1
+	var carriedInput []byte
+	for position < len(shard.Cases) {
+		batch := make([]int, 0)
+		inputs := make([][]byte, 0)
+		batchSize := 0
+		for next := position; next < len(shard.Cases); next++ {
+			index := shard.Cases[next]
+			if j.skip(index) {
+				j.Response.Result[index] = ResultCase{Verdict: VerdictSkipped}
+				continue
+			}
+
+			input := carriedInput
+			if next != carried {
+				var err error
+				input, err = j.Service.Fetcher.Get(j.Request.TestCase[index].Input)
+				if err != nil {
+					return StatusSystemFailFetchFile, err
+				}
+			}
+			carried, carriedInput = -1, nil
+
+			if len(batch) > 0 && batchSize+len(input) > BatchSizeLimit {
+				carried, carriedInput = next, input
+				break
+			}
+			batch = append(batch, next)
+			inputs = append(inputs, input)
+			batchSize += len(input)
+		}
+
+		if len(batch) == 0 {
+			break
+		}
+
+		next, statusCode, err := j.runBatch(ctx, shard, batch, inputs)
+		if deathStatusCode, deathErr := containerDeath(shard.Container, err); deathStatusCode != "" {
+			return deathStatusCode, deathErr
+		}
+		if err != nil || statusCode != "" {
+			return statusCode, err
+		}
+		position = next
+	}
+
+	return "", nil
+}
+
+// runBatch streams the results of the batch and evaluates each of them as it arrives, the batch is
+// interrupted as soon as one of its remaining cases no longer needs to run, batch holds positions
+// into the cases of the shard and the returned position is the one from which cases still have to be considered
+func (j *gradingJob) runBatch(ctx context.Context, shard *gradingShard, batch []int, inputs [][]byte) (int, StatusCode, error) {
+	// rin enforces the hard limit of each case itself, the grace period leaves it time to report back
+	deadline := time.Duration(len(batch)) * (j.Limits.TimeHard + TestGracePeriod)
+	batchContext, cancelBatchContext := context.WithTimeout(ctx, deadline)
+	defer cancelBatchContext()
+
+	hashOnly := false
+	timeLimit := j.Limits.TimeHard.Milliseconds()
+	cases := make([]*protorin.TestContext, len(batch))
+	for i := range batch {
+		cases[i] = &protorin.TestContext{
+			Source:         inputs[i],
+			OptHashOnly:    &hashOnly,
+			OptInteractive: &j.Interactive,
+			TimeLimitCpu:   &timeLimit,
+			TimeLimitWall:  &timeLimit,
+			MemoryLimit:    &j.Limits.MemorySoft,
+			OutputLimit:    &j.Limits.Output,
+		}
+	}
+
+	stopOnFailure := j.Request.Settings.StopOnFirstFailure
+	stream, err := shard.Client.TestBatch(batchContext, &protorin.TestBatchContext{Cases: cases, OptStopOnFailure: &stopOnFailure})
+	if err != nil {
+		return 0, StatusSystemFail, err
+	}
+
+	timeStart := time.Now()
+	oomKills := shard.Container.OomKills()
+	received := 0
+	for received < len(batch) {
+		data, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			grpcStatusCode, ok := status.FromError(err)
+			if ok && grpcStatusCode.Code() == codes.DeadlineExceeded {
+				return 0, StatusFailTimeoutHard, nil
+			}
+			return 0, StatusSystemFail, err
+		}
+
+		batchIndex := int(data.GetIndex())
+		if batchIndex != received {
+			return 0, StatusSystemFail, errors.New("rin returned test results out of order")
+		}
+		position := batch[batchIndex]
+		index := shard.Cases[position]
+		received++
+
+		timeElapse := time.Now().Sub(timeStart)
+		timeStart = time.Now()
+
+		// the cases of a batch run one after another, an OOM kill since the previous result belongs to this case
+		oomKilled := shard.Container.OomKills() > oomKills
+		if !oomKilled && data.GetResult().GetSignal() == int32(syscall.SIGKILL) {
+			oomKilled = shard.Container.WaitOomKill(oomKills, OomEventGracePeriod)
+		}
+		oomKills = shard.Container.OomKills()
+
+		result, statusCode, err := j.evaluate(shard, index, inputs[batchIndex], data.GetResult(), timeElapse, oomKilled)
+		if err != nil {
+			return 0, statusCode, err
+		}
+		j.record(index, result)
+
+		for _, remaining := range batch[received:] {
+			if j.skip(shard.Cases[remaining]) {
+				drainBatch(stream, cancelBatchContext)
+				return position + 1, "", nil
+			}
+		}
+	}
+
+	// rin stopped the batch by itself after a failure
+	if received < len(batch) {
+		j.stop()
+		return batch[received], "", nil
+	}
+	return batch[len(batch)-1] + 1, "", nil
+}
+
+// drainBatch interrupts a batch and waits for its stream to close, rin kills the case it is running and
+// does not start another test before that case is done
+func drainBatch(stream protorin.Rin_TestBatchClient, cancel context.CancelFunc) {
+	cancel()
+	for {
+		_, err := stream.Recv()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// evaluate turns the result reported by rin into the result of the case, oomKilled tells whether the OOM killer
+// of the container killed a process while the case ran
+func (j *gradingJob) evaluate(shard *gradingShard, index int, input []byte, data *protorin.TestResult, timeElapse time.Duration, oomKilled bool) (*ResultCase, StatusCode, error) {
+	if data.TimeWall != nil {
+		timeElapse = time.Duration(data.GetTimeWall()) * time.Microsecond
+	}
+	memoryConsumed := data.GetMemory()
+
+	killReason := data.GetKillReason()
+	caseTimeExceed := timeElapse > j.Limits.TimeSoft || data.GetTimedOut() || killReason == protorin.KillReason_KILL_REASON_TIME_LIMIT
+	caseMemoryExceed := (memoryConsumed > 0 && memoryConsumed > j.Limits.MemorySoft) || data.GetMemoryExceeded() || killReason == protorin.KillReason_KILL_REASON_MEMORY_LIMIT || oomKilled
+
+	resultEntry := ResultCase{
+		Hash:     base64.StdEncoding.EncodeToString(data.Hash),
+		Time:     timeElapse.Milliseconds(),
+		Memory:   data.GetMemory(), // proto will default to 0
+		ExitCode: data.GetExitCode(),
+		Signal:   data.GetSignal(),
+	}
+
+	switch {
+	case caseTimeExceed:
+		resultEntry.Verdict = VerdictTimeLimit
+	case caseMemoryExceed:
+		resultEntry.Verdict = VerdictMemoryLimit
+	case data.GetOutputLimitExceeded() || killReason == protorin.KillReason_KILL_REASON_OUTPUT_LIMIT:
+		resultEntry.Verdict = VerdictOutputLimit
+	case j.Interactive:
+		// the interactor decides the verdict, a crash is only reported when the interaction itself was accepted
+		checkResult := parseTestlibResult(int(data.GetInteractorExitCode()), strings.TrimSpace(string(data.GetInteractorMessage())))
+		if checkResult.Pass && (data.GetSignal() != 0 || data.GetExitCode() != 0) {
+			resultEntry.Verdict = VerdictRuntimeError
+			resultEntry.RuntimeError, resultEntry.Message = classifyRuntimeError(data)
+			break
+		}
+
+		resultEntry.Pass = checkResult.Pass
+		resultEntry.Verdict = checkResult.Verdict
+		resultEntry.Score = checkResult.Score
+		resultEntry.Message = checkResult.Message
+	case data.GetSignal() != 0 || data.GetExitCode() != 0:
+		resultEntry.Verdict = VerdictRuntimeError
+		resultEntry.RuntimeError, resultEntry.Message = classifyRuntimeError(data)
+	default:
+		outputExpected, err := j.Service.Fetcher.Get(j.Request.TestCase[index].Output)
+		if err != nil {
+			return nil, StatusSystemFailFetchFile, err
+		}
+
+		checker := j.Checkers[index]
+		if shard.Judge != nil {
+			checker = shard.Judge
+		}
+
+		checkResult, err := checker.Check(input, outputExpected, data.Result)
+		if err != nil {
+			return nil, StatusSystemFail, err
+		}
+
+		resultEntry.Pass = checkResult.Pass
+		resultEntry.Verdict = checkResult.Verdict
+		resultEntry.Score = checkResult.Score
+		resultEntry.Message = checkResult.Message
+	}
+
+	if !resultEntry.Pass {
+		resultEntry.Stderr = string(data.GetStderr())
+	}
+	return &resultEntry, "", nil
+}
@@ -0,0 +1,181 @@
+package grading
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type CheckerType string
+
+const (
+	CheckerExact           CheckerType = "exact"
+	CheckerToken           CheckerType = "token"
+	CheckerWhitespace      CheckerType = "whitespace"
+	CheckerCaseInsensitive CheckerType = "caseInsensitive"
+	CheckerFloat           CheckerType = "float"
+)
+
+type CheckerSettings struct {
+	Type            CheckerType `json:"type"`
+	EpsilonAbsolute float64     `json:"epsilonAbsolute"`
+	EpsilonRelative float64     `json:"epsilonRelative"`
+}
+
+type CheckResult struct {
+	Pass    bool
+	Verdict Verdict
+	Score   float64 // fraction of the case score awarded, between 0 and 1
+	Message string
+}
+
+// Checker decides whether the output of a test case is acceptable,
+// input is provided for checkers that need to validate against it
+type Checker interface {
+	Check(input []byte, expected []byte, actual []byte) (*CheckResult, error)
+}
+
+func NewChecker(settings *CheckerSettings) (Checker, error) {
+	if settings == nil {
+		return &ExactChecker{}, nil
+	}
+
+	switch settings.Type {
+	case "", CheckerExact:
+		return &ExactChecker{}, nil
+	case CheckerToken:
+		return &TokenChecker{}, nil
+	case CheckerWhitespace:
+		return &WhitespaceChecker{}, nil
+	case CheckerCaseInsensitive:
+		return &CaseInsensitiveChecker{}, nil
+	case CheckerFloat:
+		if settings.EpsilonAbsolute < 0 || settings.EpsilonRelative < 0 {
+			return nil, fmt.Errorf("invalid float checker epsilon: %f %f", settings.EpsilonAbsolute, settings.EpsilonRelative)
+		}
+		return &FloatChecker{
+			EpsilonAbsolute: settings.EpsilonAbsolute,
+			EpsilonRelative: settings.EpsilonRelative,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown checker type %s", settings.Type)
+	}
+}
+
+func pass() *CheckResult {
+	return &CheckResult{Pass: true, Verdict: VerdictAccepted, Score: 1}
+}
+
+func fail(format string, args ...any) *CheckResult {
+	return &CheckResult{Pass: false, Verdict: VerdictWrongAnswer, Message: fmt.Sprintf(format, args...)}
+}
+
+// ExactChecker requires the output to be byte-for-byte equal to the expected output
+type ExactChecker struct {
+}
+
+func (c *ExactChecker) Check(_ []byte, expected []byte, actual []byte) (*CheckResult, error) {
+	if !bytes.Equal(expected, actual) {
+		return fail("output differs"), nil
+	}
+	return pass(), nil
+}
+
+// compareTokens splits both outputs on whitespace and compares each pair with the given function
+func compareTokens(expected []byte, actual []byte, equal func(expected string, actual string) bool) *CheckResult {
+	expectedTokens := strings.Fields(string(expected))
+	actualTokens := strings.Fields(string(actual))
+
+	for i := 0; i < len(expectedTokens) && i < len(actualTokens); i++ {
+		if !equal(expectedTokens[i], actualTokens[i]) {
+			return fail("token %d differs", i+1)
+		}
+	}
+
+	if len(expectedTokens) != len(actualTokens) {
+		return fail("expected %d tokens, got %d", len(expectedTokens), len(actualTokens))
+	}
+	return pass()
+}
+
+// TokenChecker compares whitespace-separated tokens, ignoring the amount and kind of whitespace between them
+type TokenChecker struct {
+}
+
+func (c *TokenChecker) Check(_ []byte, expected []byte, actual []byte) (*CheckResult, error) {
+	return compareTokens(expected, actual, func(expected string, actual string) bool {
+		return expected == actual
+	}), nil
+}
+
+// WhitespaceChecker compares line by line, ignoring leading and trailing whitespace,
+// the amount of whitespace between words and trailing empty lines
+type WhitespaceChecker struct {
+}
+
+func normalizeLines(data []byte) []string {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func (c *WhitespaceChecker) Check(_ []byte, expected []byte, actual []byte) (*CheckResult, error) {
+	expectedLines := normalizeLines(expected)
+	actualLines := normalizeLines(actual)
+
+	for i := 0; i < len(expectedLines) && i < len(actualLines); i++ {
+		if expectedLines[i] != actualLines[i] {
+			return fail("line %d differs", i+1), nil
+		}
+	}
+
+	if len(expectedLines) != len(actualLines) {
+		return fail("expected %d lines, got %d", len(expectedLines), len(actualLines)), nil
+	}
+	return pass(), nil
+}
+
+// CaseInsensitiveChecker compares whitespace-separated tokens without regard to letter case
+type CaseInsensitiveChecker struct {
+}
+
+func (c *CaseInsensitiveChecker) Check(_ []byte, expected []byte, actual []byte) (*CheckResult, error) {
+	return compareTokens(expected, actual, strings.EqualFold), nil
+}
+
+// FloatChecker compares whitespace-separated tokens, tokens that parse as numbers are accepted
+// when they are within either the absolute or the relative epsilon of the expected value
+type FloatChecker struct {
+	EpsilonAbsolute float64
+	EpsilonRelative float64
+}
+
+func (c *FloatChecker) equal(expected string, actual string) bool {
+	if expected == actual {
+		return true
+	}
+
+	expectedValue, err := strconv.ParseFloat(expected, 64)
+	if err != nil {
+		return false
+	}
+	actualValue, err := strconv.ParseFloat(actual, 64)
+	if err != nil || math.IsNaN(actualValue) || math.IsInf(actualValue, 0) {
+		return false
+	}
+
+	difference := math.Abs(expectedValue - actualValue)
+	return difference <= c.EpsilonAbsolute || difference <= c.EpsilonRelative*math.Abs(expectedValue)
+}
+
+func (c *FloatChecker) Check(_ []byte, expected []byte, actual []byte) (*CheckResult, error) {
+	return compareTokens(expected, actual, c.equal), nil
+}
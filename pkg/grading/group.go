@@ -0,0 +1,199 @@
+package grading
+
+import (
+	"fmt"
+	"math"
+)
+
+type ScoringPolicy string
+
+const (
+	ScoringSum          ScoringPolicy = "sum" // weight scaled by the average case score
+	ScoringMin          ScoringPolicy = "min" // weight scaled by the lowest case score
+	ScoringAllOrNothing ScoringPolicy = "all" // full weight only if every case passes
+)
+
+type TestGroup struct {
+	Name         string        `json:"name"`
+	Cases        []int         `json:"cases"` // indices into Request.TestCase
+	Weight       float64       `json:"weight"`
+	Policy       ScoringPolicy `json:"policy"`
+	Dependencies []string      `json:"dependencies"` // earlier groups that must pass entirely for this group to score
+	SkipOnFail   bool          `json:"skipOnFail"`   // skip remaining cases once the group can no longer score
+}
+
+type ResultGroup struct {
+	Name     string  `json:"name"`
+	Score    float64 `json:"score"`
+	MaxScore float64 `json:"maxScore"`
+	Pass     bool    `json:"pass"`
+}
+
+// groupTracker keeps the state of each test group while the cases are being run,
+// so that cases of groups which can no longer score can be skipped
+type groupTracker struct {
+	groups       []TestGroup
+	dependencies [][]int
+	caseGroups   [][]int
+	failed       []bool // at least one case did not pass
+	zero         []bool // at least one case scored nothing
+}
+
+func newGroupTracker(groups []TestGroup, caseCount int) (*groupTracker, error) {
+	tracker := &groupTracker{
+		groups:       append([]TestGroup(nil), groups...),
+		dependencies: make([][]int, len(groups)),
+		caseGroups:   make([][]int, caseCount),
+		failed:       make([]bool, len(groups)),
+		zero:         make([]bool, len(groups)),
+	}
+
+	nameIndex := make(map[string]int)
+	for index, group := range groups {
+		if group.Name == "" {
+			return nil, fmt.Errorf("test group %d has no name", index)
+		}
+		if _, ok := nameIndex[group.Name]; ok {
+			return nil, fmt.Errorf("duplicated test group %s", group.Name)
+		}
+
+		switch group.Policy {
+		case "":
+			tracker.groups[index].Policy = ScoringSum
+		case ScoringSum, ScoringMin, ScoringAllOrNothing:
+		default:
+			return nil, fmt.Errorf("unknown scoring policy %s for test group %s", group.Policy, group.Name)
+		}
+
+		if group.Weight < 0 || math.IsNaN(group.Weight) || math.IsInf(group.Weight, 0) {
+			return nil, fmt.Errorf("invalid weight %f for test group %s", group.Weight, group.Name)
+		}
+
+		for _, caseIndex := range group.Cases {
+			if caseIndex < 0 || caseIndex >= caseCount {
+				return nil, fmt.Errorf("test group %s refers to non-existing case %d", group.Name, caseIndex)
+			}
+			tracker.caseGroups[caseIndex] = append(tracker.caseGroups[caseIndex], index)
+		}
+
+		for _, dependency := range group.Dependencies {
+			dependencyIndex, ok := nameIndex[dependency]
+			if !ok {
+				return nil, fmt.Errorf("test group %s depends on %s which is not an earlier group", group.Name, dependency)
+			}
+			tracker.dependencies[index] = append(tracker.dependencies[index], dependencyIndex)
+		}
+
+		nameIndex[group.Name] = index
+	}
+
+	return tracker, nil
+}
+
+// unsatisfied reports whether the group or any of its dependencies already has a failing case
+func (t *groupTracker) unsatisfied(group int) bool {
+	if t.failed[group] {
+		return true
+	}
+
+	for _, dependency := range t.dependencies[group] {
+		if t.unsatisfied(dependency) {
+			return true
+		}
+	}
+	return false
+}
+
+// dead reports whether the group can no longer score regardless of the remaining cases
+func (t *groupTracker) dead(group int) bool {
+	switch t.groups[group].Policy {
+	case ScoringAllOrNothing:
+		if t.failed[group] {
+			return true
+		}
+	case ScoringMin:
+		if t.zero[group] {
+			return true
+		}
+	}
+
+	for _, dependency := range t.dependencies[group] {
+		if t.unsatisfied(dependency) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldSkip reports whether running the case cannot change the score anymore,
+// cases which do not belong to any group are always run
+func (t *groupTracker) ShouldSkip(caseIndex int) bool {
+	groups := t.caseGroups[caseIndex]
+	if len(groups) == 0 {
+		return false
+	}
+
+	for _, group := range groups {
+		if !t.groups[group].SkipOnFail || !t.dead(group) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *groupTracker) Record(caseIndex int, result *ResultCase) {
+	for _, group := range t.caseGroups[caseIndex] {
+		t.failed[group] = t.failed[group] || !result.Pass
+		t.zero[group] = t.zero[group] || result.Score <= 0
+	}
+}
+
+// Score computes the result of every group from the final case results along with the total score
+func (t *groupTracker) Score(results []ResultCase) ([]ResultGroup, float64, float64) {
+	resultGroups := make([]ResultGroup, len(t.groups))
+	satisfied := make([]bool, len(t.groups))
+	total := 0.0
+	totalMax := 0.0
+
+	for index, group := range t.groups {
+		caseScoreSum := 0.0
+		caseScoreMin := 1.0
+		pass := true
+		for _, caseIndex := range group.Cases {
+			caseScoreSum += results[caseIndex].Score
+			caseScoreMin = math.Min(caseScoreMin, results[caseIndex].Score)
+			pass = pass && results[caseIndex].Pass
+		}
+
+		dependencySatisfied := true
+		for _, dependency := range t.dependencies[index] {
+			dependencySatisfied = dependencySatisfied && satisfied[dependency]
+		}
+		satisfied[index] = pass && dependencySatisfied
+
+		score := 0.0
+		if dependencySatisfied && len(group.Cases) > 0 {
+			switch group.Policy {
+			case ScoringSum:
+				score = group.Weight * caseScoreSum / float64(len(group.Cases))
+			case ScoringMin:
+				score = group.Weight * caseScoreMin
+			case ScoringAllOrNothing:
+				if pass {
+					score = group.Weight
+				}
+			}
+		}
+
+		resultGroups[index] = ResultGroup{
+			Name:     group.Name,
+			Score:    score,
+			MaxScore: group.Weight,
+			Pass:     satisfied[index],
+		}
+		total += score
+		totalMax += group.Weight
+	}
+
+	return resultGroups, total, totalMax
+}
@@ -0,0 +1,83 @@
+package grading
+
+import (
+	"GradingCore2/pkg/protorin"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckerTimeLimit bounds each run of a special judge, a checker looping forever must not hold the grade
+// and its slot of the gateway concurrency
+const CheckerTimeLimit = 10 * time.Second
+
+// exit codes used by testlib checkers
+const (
+	testlibOk            = 0
+	testlibWrongAnswer   = 1
+	testlibPresentation  = 2
+	testlibFail          = 3
+	testlibDirt          = 4
+	testlibPoints        = 5
+	testlibUnexpectedEof = 8
+	testlibPartially     = 16
+)
+
+// SpecialJudgeChecker runs a problem-setter-supplied checker program which has already been
+// compiled inside the runner container through the Rin CompileChecker call
+type SpecialJudgeChecker struct {
+	Context context.Context
+	Client  protorin.RinClient
+}
+
+func (c *SpecialJudgeChecker) Check(input []byte, expected []byte, actual []byte) (*CheckResult, error) {
+	ctx, cancel := context.WithTimeout(c.Context, CheckerTimeLimit)
+	defer cancel()
+
+	result, err := c.Client.Check(ctx, &protorin.CheckContext{
+		Input:    input,
+		Expected: expected,
+		Output:   actual,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTestlibResult(int(result.GetExitCode()), strings.TrimSpace(string(result.GetMessage()))), nil
+}
+
+func parseTestlibResult(exitCode int, message string) *CheckResult {
+	switch {
+	case exitCode == testlibOk:
+		return &CheckResult{Pass: true, Verdict: VerdictAccepted, Score: 1, Message: message}
+	case exitCode == testlibWrongAnswer || exitCode == testlibDirt || exitCode == testlibUnexpectedEof:
+		return &CheckResult{Verdict: VerdictWrongAnswer, Message: message}
+	case exitCode == testlibPresentation:
+		return &CheckResult{Verdict: VerdictPresentationError, Message: message}
+	case exitCode == testlibPoints:
+		// quitp reports the points as the first token of the message
+		fields := strings.Fields(message)
+		if len(fields) > 0 {
+			points, err := strconv.ParseFloat(fields[0], 64)
+			if err == nil {
+				return partial(points, message)
+			}
+		}
+		return &CheckResult{Verdict: VerdictCheckerFail, Message: message}
+	case exitCode >= testlibPartially && exitCode <= testlibPartially+100:
+		return partial(float64(exitCode-testlibPartially)/100, message)
+	default:
+		return &CheckResult{Verdict: VerdictCheckerFail, Message: message}
+	}
+}
+
+func partial(score float64, message string) *CheckResult {
+	if score >= 1 {
+		return &CheckResult{Pass: true, Verdict: VerdictAccepted, Score: 1, Message: message}
+	}
+	if score <= 0 {
+		return &CheckResult{Verdict: VerdictWrongAnswer, Message: message}
+	}
+	return &CheckResult{Verdict: VerdictPartial, Score: score, Message: message}
+}
@@ -0,0 +1,97 @@
+package grading
+
+import (
+	"GradingCore2/pkg/protorin"
+	"GradingCore2/pkg/runner"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+var ErrNoSelfTest = errors.New("no self test for this template")
+
+// SelfTestOutput is what every self test program prints
+const SelfTestOutput = "hello world"
+
+// selfTestSources are built-in programs checking that a template compiles and runs code, by template id
+var selfTestSources = map[string]string{
+	"c": `#include <stdio.h>
+int main(void) {
+	printf("hello world\n");
+	return 0;
+}
+`,
+	"cpp": `#include <iostream>
+int main() {
+	std::cout << "hello world" << std::endl;
+	return 0;
+}
+`,
+	"go": `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("hello world")
+}
+`,
+}
+
+// SelfTest compiles and runs a hello world program through rin in a container of the template
+func (s *Service) SelfTest(ctx context.Context, template *runner.ContainerTemplate) error {
+	source, ok := selfTestSources[template.Id]
+	if !ok {
+		return ErrNoSelfTest
+	}
+
+	runnerContainer, err := s.RunnerService.Create(ctx, template)
+	if err != nil {
+		return err
+	}
+	// the health check runs before the runner service ticks, a released container would keep its slot until then
+	defer func() {
+		err := s.RunnerService.Destroy(context.Background(), runnerContainer)
+		if err != nil {
+			log.Println("failed to destroy self test container", err)
+		}
+	}()
+
+	shard, compileOutput, statusCode, err := s.prepareShard(ctx, ctx, &Request{}, runnerContainer, &submissionSources{Source: []byte(source)})
+	if err != nil {
+		return fmt.Errorf("%s: %w", statusCode, err)
+	}
+	if statusCode != "" {
+		return fmt.Errorf("%s: %s", statusCode, compileOutput)
+	}
+
+	timeLimit := s.TimeLimitHardUser.Milliseconds()
+	outputLimit := s.OutputLimitHard * 1024
+	result, err := shard.Client.Test(ctx, &protorin.TestContext{
+		Source:        []byte{},
+		TimeLimitCpu:  &timeLimit,
+		TimeLimitWall: &timeLimit,
+		MemoryLimit:   &s.MemoryLimitHard,
+		OutputLimit:   &outputLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if result.GetExitCode() != 0 || result.GetTimedOut() || result.GetMemoryExceeded() {
+		return fmt.Errorf("self test exited with %d, timed out %t, memory exceeded %t: %s",
+			result.GetExitCode(), result.GetTimedOut(), result.GetMemoryExceeded(), result.GetStderr())
+	}
+
+	output := strings.TrimSpace(string(result.GetResult()))
+	if output != SelfTestOutput {
+		return fmt.Errorf("self test printed %q instead of %q", output, SelfTestOutput)
+	}
+	return nil
+}
+
+// DisableTemplate makes the submissions of a template fail right away, such as when it failed its self test
+func (s *Service) DisableTemplate(id string, reason error) {
+	s.DisabledTemplates[id] = reason
+}
@@ -4,50 +4,75 @@ import (
 	"GradingCore2/pkg/fetcher"
 	"GradingCore2/pkg/protorin"
 	"GradingCore2/pkg/runner"
-	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
 	"fmt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"log"
 	"strings"
+	"sync"
 	"time"
 )
 
 type TestCase struct {
-	Input  string `json:"input"`
-	Output string `json:"output"`
+	Input   string           `json:"input"`
+	Output  string           `json:"output"`
+	Checker *CheckerSettings `json:"checker"` // overrides the checker of the request for this case
 }
 
 type ResultCase struct {
-	Hash   string `json:"hash"`
-	Pass   bool   `json:"pass"`
-	Time   int64  `json:"time"`
-	Memory int64  `json:"memory"`
+	Hash         string  `json:"hash"`
+	Pass         bool    `json:"pass"`
+	Time         int64   `json:"time"`
+	Memory       int64   `json:"memory"`
+	Verdict      Verdict `json:"verdict"`
+	ExitCode     int32   `json:"exitCode"`
+	Signal       int32   `json:"signal"`
+	RuntimeError string  `json:"runtimeError,omitempty"` // terminating signal name such as SIGSEGV, or NONZERO_EXIT
+	Score        float64 `json:"score"`
+	Message      string  `json:"message,omitempty"`
+	Stderr       string  `json:"stderr,omitempty"` // excerpt of the standard error, only reported for failing cases
 }
 
 type RequestSettings struct {
-	TimeLimit   int `json:"timeLimit"`
-	MemoryLimit int `json:"memoryLimit"`
+	TimeLimit          int  `json:"timeLimit"`
+	MemoryLimit        int  `json:"memoryLimit"`
+	OutputLimit        int  `json:"outputLimit"`        // output limit in KiB, the hard limit applies if not set
+	StopOnFirstFailure bool `json:"stopOnFirstFailure"` // skip every remaining case once one does not pass
 }
 
 type Request struct {
-	Language  string                 `json:"language"`
-	SourceUrl string                 `json:"sourceUrl"`
-	TestCase  []TestCase             `json:"test"`
-	Settings  RequestSettings        `json:"settings"`
-	Metadata  map[string]interface{} `json:"metadata"`
+	Language      string                 `json:"language"`
+	SourceUrl     string                 `json:"sourceUrl"`
+	TestCase      []TestCase             `json:"test"`
+	Checker       *CheckerSettings       `json:"checker"`
+	CheckerUrl    string                 `json:"checkerUrl"`    // special judge source, takes precedence over Checker
+	InteractorUrl string                 `json:"interactorUrl"` // interactor source, makes the problem interactive
+	Groups        []TestGroup            `json:"groups"`
+	Settings      RequestSettings        `json:"settings"`
+	Metadata      map[string]interface{} `json:"metadata"`
 }
 
 type Response struct {
 	CompileOutput string                 `json:"compileOutput"`
 	Status        StatusCode             `json:"status"`
 	Result        []ResultCase           `json:"results"`
+	Groups        []ResultGroup          `json:"groups,omitempty"`
+	Score         float64                `json:"score"`
+	MaxScore      float64                `json:"maxScore"`
 	Metadata      map[string]interface{} `json:"metadata"`
+	Cpus          []int                  `json:"cpus,omitempty"` // CPUs the containers grading the submission were pinned to
 }
 
+// ContainerExitGracePeriod is how long a failed call to rin waits for the runner to report its container as exited
+const ContainerExitGracePeriod = time.Second
+
+// OomEventGracePeriod is how long a case killed by SIGKILL waits for the runner to report an OOM kill of the container
+const OomEventGracePeriod = 200 * time.Millisecond
+
+// TestGracePeriod is added to the deadline of each test call on top of the limit enforced by rin
+const TestGracePeriod = 2 * time.Second
+
 type TemplateMap map[string]*runner.ContainerTemplate
 
 type Service struct {
@@ -57,6 +82,8 @@ type Service struct {
 	TimeLimitHardUser   time.Duration
 	TimeLimitHardSystem time.Duration
 	MemoryLimitHard     int64
+	OutputLimitHard     int64
+	DisabledTemplates   map[string]error // templates which failed their startup check, by id
 }
 
 func (r *Response) WrapStatus(status StatusCode) (*Response, *Error) {
@@ -72,13 +99,15 @@ func (r *Response) WrapError(status StatusCode, err error) (*Response, *Error) {
 	}
 }
 
-func NewService(runnerService *runner.Service, templateMap TemplateMap, timeLimitHardUser int64, timeLimitHardSystem int64, memoryLimitHard int64) (*Service, error) {
+func NewService(runnerService *runner.Service, templateMap TemplateMap, timeLimitHardUser int64, timeLimitHardSystem int64, memoryLimitHard int64, outputLimitHard int64) (*Service, error) {
 	return &Service{
 		RunnerService:       runnerService,
 		TemplateMap:         templateMap,
 		TimeLimitHardUser:   time.Duration(timeLimitHardUser) * time.Millisecond,
 		TimeLimitHardSystem: time.Duration(timeLimitHardSystem) * time.Millisecond,
 		MemoryLimitHard:     memoryLimitHard,
+		OutputLimitHard:     outputLimitHard,
+		DisabledTemplates:   make(map[string]error),
 	}, nil
 }
 
@@ -103,6 +132,12 @@ func (s *Service) Grade(ctx context.Context, req *Request) (*Response, *Error) {
 		memoryLimitSoft = s.MemoryLimitHard
 	}
 
+	outputLimit := int64(req.Settings.OutputLimit)
+	if outputLimit <= 0 || outputLimit > s.OutputLimitHard {
+		outputLimit = s.OutputLimitHard
+	}
+	outputLimit = outputLimit * 1024
+
 	log.Println("grading", req.SourceUrl, " limits: ", caseTimeLimitSoft, caseTimeLimitHard)
 
 	timedSystemContext, cancelTimedSetupContext := context.WithTimeout(ctx, s.TimeLimitHardSystem)
@@ -116,103 +151,250 @@ func (s *Service) Grade(ctx context.Context, req *Request) (*Response, *Error) {
 	}
 	req.Language = strings.ToLower(req.Language)
 
+	checkers := make([]Checker, len(req.TestCase))
+	for index, test := range req.TestCase {
+		checkerSettings := req.Checker
+		if test.Checker != nil {
+			checkerSettings = test.Checker
+		}
+
+		checker, err := NewChecker(checkerSettings)
+		if err != nil {
+			return resp.WrapError(StatusSystemFailInvalidRequest, fmt.Errorf("test case %d: %w", index, err))
+		}
+		checkers[index] = checker
+	}
+
+	groupTracker, err := newGroupTracker(req.Groups, len(req.TestCase))
+	if err != nil {
+		return resp.WrapError(StatusSystemFailInvalidRequest, err)
+	}
+
+	if reason := s.DisabledTemplates[req.Language]; reason != nil {
+		return resp.WrapError(StatusSystemFailMissingImage, fmt.Errorf("template for language %s is disabled: %w", req.Language, reason))
+	}
+
 	template := s.TemplateMap[req.Language]
 	if template == nil {
 		return resp.WrapError(StatusSystemFailMissingImage, fmt.Errorf("template for language %s not found", req.Language))
 	}
 
+	shardCount := template.Parallelism
+	if shardCount > len(req.TestCase) {
+		shardCount = len(req.TestCase)
+	}
+	if shardCount < 1 {
+		shardCount = 1
+	}
+
+	containers := make([]*runner.ContainerInfo, 0, shardCount)
+	defer func() {
+		for _, runnerContainer := range containers {
+			s.RunnerService.Release(runnerContainer)
+		}
+	}()
+
 	runnerContainer, err := s.RunnerService.Create(timedSystemContext, template)
 	if err != nil {
 		return resp.WrapError(StatusSystemFailContainer, err)
 	}
+	containers = append(containers, runnerContainer)
 
-	defer func() {
-		destroyErr := s.RunnerService.Destroy(ctx, runnerContainer)
-		if destroyErr != nil {
-			log.Println("failed to destroy container", runnerContainer.ContainerId, destroyErr)
+	// extra shards are only worth it while containers are available, the submission still runs on fewer of them
+	for len(containers) < shardCount {
+		extraContainer, err := s.RunnerService.TryCreate(timedSystemContext, template)
+		if err != nil {
+			log.Println("grading with", len(containers), "of", shardCount, "containers:", err)
+			break
 		}
-	}()
+		containers = append(containers, extraContainer)
+	}
+
+	for _, runnerContainer := range containers {
+		if runnerContainer.Cpu >= 0 {
+			resp.Cpus = append(resp.Cpus, runnerContainer.Cpu)
+		}
+	}
+
+	sources := submissionSources{}
+	sources.Source, err = s.Fetcher.Get(req.SourceUrl)
+	if err != nil {
+		return resp.WrapError(StatusSystemFailFetchFile, err)
+	}
+
+	if req.CheckerUrl != "" {
+		sources.Checker, err = s.Fetcher.Get(req.CheckerUrl)
+		if err != nil {
+			return resp.WrapError(StatusSystemFailFetchFile, err)
+		}
+	}
+
+	interactive := req.InteractorUrl != ""
+	if interactive {
+		sources.Interactor, err = s.Fetcher.Get(req.InteractorUrl)
+		if err != nil {
+			return resp.WrapError(StatusSystemFailFetchFile, err)
+		}
+	}
+
+	shards := make([]*gradingShard, len(containers))
+	compileOutputs := make([]string, len(containers))
+	statusCodes := make([]StatusCode, len(containers))
+	errs := make([]error, len(containers))
+	wg := sync.WaitGroup{}
+	for i, runnerContainer := range containers {
+		wg.Add(1)
+		go func(i int, runnerContainer *runner.ContainerInfo) {
+			defer wg.Done()
+			shards[i], compileOutputs[i], statusCodes[i], errs[i] = s.prepareShard(timedSystemContext, ctx, req, runnerContainer, &sources)
+		}(i, runnerContainer)
+	}
+	wg.Wait()
+
+	resp.CompileOutput = compileOutputs[0]
+	for i := range containers {
+		if errs[i] != nil {
+			return resp.WrapError(statusCodes[i], errs[i])
+		}
+		if statusCodes[i] != "" {
+			return resp.WrapStatus(statusCodes[i])
+		}
+	}
+
+	// cases are dealt round-robin so that every shard progresses through the groups at the same pace
+	for index := range req.TestCase {
+		shard := shards[index%len(shards)]
+		shard.Cases = append(shard.Cases, index)
+	}
+
+	job := gradingJob{
+		Service:  s,
+		Request:  req,
+		Response: &resp,
+		Limits: caseLimits{
+			TimeSoft:   caseTimeLimitSoft,
+			TimeHard:   caseTimeLimitHard,
+			MemorySoft: memoryLimitSoft,
+			Output:     outputLimit,
+		},
+		Checkers:    checkers,
+		Tracker:     groupTracker,
+		Interactive: interactive,
+	}
+	statusCode, err := job.RunSharded(ctx, shards)
+	if err != nil {
+		return resp.WrapError(statusCode, err)
+	}
+	if statusCode != "" {
+		return resp.WrapStatus(statusCode)
+	}
+
+	if len(req.Groups) > 0 {
+		resp.Groups, resp.Score, resp.MaxScore = groupTracker.Score(resp.Result)
+	} else {
+		for _, result := range resp.Result {
+			resp.Score += result.Score
+		}
+		resp.MaxScore = float64(len(resp.Result))
+	}
+
+	resp.Status = StatusCompleted
+	for _, result := range resp.Result {
+		switch {
+		case result.Verdict == VerdictCheckerFail && interactive:
+			// the interactor is the checker of an interactive problem
+			return resp.WrapStatus(StatusSystemFailInteractor)
+		case result.Verdict == VerdictCheckerFail:
+			return resp.WrapStatus(StatusSystemFailChecker)
+		case result.Verdict == VerdictTimeLimit:
+			resp.Status = StatusFailTimeout
+		case result.Verdict == VerdictMemoryLimit && resp.Status != StatusFailTimeout:
+			resp.Status = StatusFailMemory
+		}
+	}
+	return &resp, nil
+}
 
+// submissionSources are the programs compiled in every container of a submission,
+// Checker and Interactor are only set when the request provides them
+type submissionSources struct {
+	Source     []byte
+	Checker    []byte
+	Interactor []byte
+}
+
+// prepareShard waits for the container to be up and hardened then compiles the submission, the checker and the interactor in it
+func (s *Service) prepareShard(systemContext context.Context, ctx context.Context, req *Request, runnerContainer *runner.ContainerInfo, sources *submissionSources) (*gradingShard, string, StatusCode, error) {
 	containerStartSuccess, err := runnerContainer.Wait(s.TimeLimitHardSystem)
 	if !containerStartSuccess {
-		return resp.WrapError(StatusSystemFailContainerPing, err)
+		return nil, "", StatusSystemFailContainerPing, err
 	}
 
-	source, err := s.Fetcher.Get(req.SourceUrl)
+	err = s.RunnerService.Verify(systemContext, runnerContainer)
 	if err != nil {
-		return resp.WrapError(StatusSystemFailFetchFile, err)
+		return nil, "", StatusSystemFailContainer, err
 	}
 
-	compile, err := runnerContainer.GrpcClient.Compile(timedSystemContext, &protorin.Source{Source: source})
+	compileOutput := ""
+	compile, err := runnerContainer.GrpcClient.Compile(systemContext, &protorin.Source{Source: sources.Source})
 	if compile != nil && compile.Data != nil {
-		resp.CompileOutput = string(compile.Data)
+		compileOutput = string(compile.Data)
 	}
 	if err != nil || !*compile.Success {
 		fromError, ok := status.FromError(err)
 		if ok && fromError.Code() == codes.DeadlineExceeded {
-			return resp.WrapError(StatusFailCompilationTimeout, err)
-		} else {
-			return resp.WrapStatus(StatusFailCompilation)
+			return nil, compileOutput, StatusFailCompilationTimeout, err
 		}
+		if statusCode, deathErr := containerDeath(runnerContainer, err); statusCode != "" {
+			return nil, compileOutput, statusCode, deathErr
+		}
+		return nil, compileOutput, StatusFailCompilation, nil
 	}
 
-	timeExceedAtLeastOnce := false
-	memoryExceedAtLeastOnce := false
-
-	for index, test := range req.TestCase {
-		input, err := s.Fetcher.Get(test.Input)
+	shard := &gradingShard{Client: runnerContainer.GrpcClient, Container: runnerContainer}
+	if req.CheckerUrl != "" {
+		checkerCompile, err := runnerContainer.GrpcClient.CompileChecker(systemContext, &protorin.Source{Source: sources.Checker})
 		if err != nil {
-			return resp.WrapError(StatusSystemFailFetchFile, err)
+			return nil, compileOutput, StatusSystemFailChecker, err
 		}
-		outputExpected, err := s.Fetcher.Get(test.Output)
-		if err != nil {
-			return resp.WrapError(StatusSystemFailFetchFile, err)
+		if !checkerCompile.GetSuccess() {
+			return nil, compileOutput, StatusSystemFailChecker, fmt.Errorf("checker compilation failed: %s", checkerCompile.Data)
 		}
 
-		outputExpectedHashProcessor := sha256.New()
-		outputExpectedHashProcessor.Write(outputExpected)
-		outputExpectedHash := outputExpectedHashProcessor.Sum(nil)
-
-		timedCaseContext, cancelTimedCaseContext := context.WithTimeoutCause(ctx, caseTimeLimitHard, &Error{ErrorCode: StatusFailTimeoutHard, Wrap: nil})
-
-		hashOnly := false
-		timeStart := time.Now()
-		data, err := runnerContainer.GrpcClient.Test(timedCaseContext, &protorin.TestContext{Source: input, OptHashOnly: &hashOnly})
-		cancelTimedCaseContext()
+		shard.Judge = &SpecialJudgeChecker{Context: ctx, Client: runnerContainer.GrpcClient}
+	}
 
+	if req.InteractorUrl != "" {
+		interactorCompile, err := runnerContainer.GrpcClient.CompileInteractor(systemContext, &protorin.Source{Source: sources.Interactor})
 		if err != nil {
-			grpcStatusCode, ok := status.FromError(err)
-			if ok && grpcStatusCode.Code() == codes.DeadlineExceeded {
-				return resp.WrapStatus(StatusFailTimeoutHard)
-			} else {
-				return resp.WrapError(StatusSystemFail, err)
-			}
+			return nil, compileOutput, StatusSystemFailInteractor, err
 		}
-		timeElapse := time.Now().Sub(timeStart)
-		memoryConsumed := data.GetMemory()
-
-		caseTimeExceed := timeElapse > caseTimeLimitSoft
-		caseMemoryExceed := memoryConsumed > 0 && memoryConsumed > memoryLimitSoft
+		if !interactorCompile.GetSuccess() {
+			return nil, compileOutput, StatusSystemFailInteractor, fmt.Errorf("interactor compilation failed: %s", interactorCompile.Data)
+		}
+	}
 
-		timeExceedAtLeastOnce = timeExceedAtLeastOnce || caseTimeExceed
-		memoryExceedAtLeastOnce = memoryExceedAtLeastOnce || caseMemoryExceed
+	err = s.RunnerService.Isolate(systemContext, runnerContainer)
+	if err != nil {
+		return nil, compileOutput, StatusSystemFailContainer, err
+	}
 
-		resultEntry := ResultCase{
-			Pass:   !caseTimeExceed && !caseMemoryExceed && bytes.Equal(data.Hash, outputExpectedHash),
-			Hash:   base64.StdEncoding.EncodeToString(data.Hash),
-			Time:   timeElapse.Milliseconds(),
-			Memory: data.GetMemory(), // proto will default to 0
-		}
+	return shard, compileOutput, "", nil
+}
 
-		resp.Result[index] = resultEntry
+// containerDeath explains a failed call to rin by the death of its container, the status is empty if the container
+// is still up, running out of memory is the fault of the submission while any other death is a system failure
+func containerDeath(runnerContainer *runner.ContainerInfo, err error) (StatusCode, error) {
+	// only a failed call to rin can be caused by the death of its container
+	if _, ok := status.FromError(err); err == nil || !ok || status.Code(err) == codes.Canceled {
+		return "", nil
+	}
+	if !runnerContainer.WaitExited(ContainerExitGracePeriod) {
+		return "", nil
 	}
 
-	if timeExceedAtLeastOnce {
-		resp.Status = StatusFailTimeout
-	} else if memoryExceedAtLeastOnce {
-		resp.Status = StatusFailMemory
-	} else {
-		resp.Status = StatusCompleted
+	if runnerContainer.OomKilled() {
+		return StatusFailMemory, nil
 	}
-	return &resp, nil
+	return StatusSystemFailContainerDied, fmt.Errorf("container %s died: %w", runnerContainer.ContainerId, err)
 }
@@ -13,12 +13,16 @@ const (
 	StatusFailTimeoutHard        StatusCode = "FAIL_TIMEOUT_HARD"
 	StatusFailMemory             StatusCode = "FAIL_MEMORY"
 
-	StatusSystemFail              StatusCode = "SYSTEM_FAIL"
-	StatusSystemFailMissingImage  StatusCode = "SYSTEM_FAIL_MISSING_IMAGE"
-	StatusSystemFailFetchFile     StatusCode = "SYSTEM_FAIL_FETCH_FILE"
-	StatusSystemFailContainer     StatusCode = "SYSTEM_FAIL_CONTAINER"
-	StatusSystemFailContainerPing StatusCode = "SYSTEM_FAIL_CONTAINER_PING"
-	StatusSystemFailRetryExceed   StatusCode = "SYSTEM_FAIL_RETRY_EXCEED"
+	StatusSystemFail               StatusCode = "SYSTEM_FAIL"
+	StatusSystemFailMissingImage   StatusCode = "SYSTEM_FAIL_MISSING_IMAGE"
+	StatusSystemFailFetchFile      StatusCode = "SYSTEM_FAIL_FETCH_FILE"
+	StatusSystemFailContainer      StatusCode = "SYSTEM_FAIL_CONTAINER"
+	StatusSystemFailContainerPing  StatusCode = "SYSTEM_FAIL_CONTAINER_PING"
+	StatusSystemFailContainerDied  StatusCode = "SYSTEM_FAIL_CONTAINER_DIED"
+	StatusSystemFailRetryExceed    StatusCode = "SYSTEM_FAIL_RETRY_EXCEED"
+	StatusSystemFailInvalidRequest StatusCode = "SYSTEM_FAIL_INVALID_REQUEST"
+	StatusSystemFailChecker        StatusCode = "SYSTEM_FAIL_CHECKER"
+	StatusSystemFailInteractor     StatusCode = "SYSTEM_FAIL_INTERACTOR"
 
 	StatusUnknown StatusCode = "UNKNOWN"
 )
@@ -0,0 +1,35 @@
+package grading
+
+import (
+	"GradingCore2/pkg/protorin"
+	"fmt"
+)
+
+type Verdict string
+
+const (
+	VerdictAccepted          Verdict = "AC"
+	VerdictWrongAnswer       Verdict = "WA"
+	VerdictPresentationError Verdict = "PE"
+	VerdictPartial           Verdict = "PC"
+	VerdictCheckerFail       Verdict = "CF"
+	VerdictTimeLimit         Verdict = "TLE"
+	VerdictMemoryLimit       Verdict = "MLE"
+	VerdictOutputLimit       Verdict = "OLE"
+	VerdictRuntimeError      Verdict = "RE"
+	VerdictSkipped           Verdict = "SK"
+)
+
+const RuntimeErrorNonZeroExit = "NONZERO_EXIT"
+
+// classifyRuntimeError names the cause of an abnormal termination along with a human-readable description
+func classifyRuntimeError(result *protorin.TestResult) (string, string) {
+	if result.GetSignal() != 0 {
+		signalName := result.GetSignalName()
+		if signalName == "" {
+			signalName = fmt.Sprintf("SIG%d", result.GetSignal())
+		}
+		return signalName, fmt.Sprintf("terminated by %s", signalName)
+	}
+	return RuntimeErrorNonZeroExit, fmt.Sprintf("exited with code %d", result.GetExitCode())
+}
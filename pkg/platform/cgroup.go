@@ -0,0 +1,164 @@
+//go:build linux
+
+package platform
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Cgroup is a cgroup v2 directory which processes can be started in
+type Cgroup struct {
+	Path string
+}
+
+// SetupCgroup prepares a delegated cgroup to host per-process children, the calling process is moved into a
+// leaf of its own since cgroup v2 does not allow processes in a cgroup whose controllers are delegated
+func SetupCgroup(path string) (*Cgroup, error) {
+	root := &Cgroup{Path: path}
+	supervisor, err := root.Child("supervisor")
+	if err != nil {
+		return nil, err
+	}
+
+	err = supervisor.write("cgroup.procs", strconv.Itoa(os.Getpid()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to move into cgroup %s: %w", supervisor.Path, err)
+	}
+
+	err = root.write("cgroup.subtree_control", "+memory")
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable controllers in cgroup %s: %w", root.Path, err)
+	}
+	return root, nil
+}
+
+func (c *Cgroup) write(file string, value string) error {
+	return os.WriteFile(filepath.Join(c.Path, file), []byte(value), 0644)
+}
+
+func (c *Cgroup) read(file string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(c.Path, file))
+}
+
+// readKey reads a value from a flat keyed file such as memory.events
+func (c *Cgroup) readKey(file string, key string) (int64, error) {
+	data, err := c.read(file)
+	if err != nil {
+		return 0, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == key {
+			return strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("key %s not found in %s", key, file)
+}
+
+// Child creates a sub-cgroup, an existing one with the same name is reused
+func (c *Cgroup) Child(name string) (*Cgroup, error) {
+	child := &Cgroup{Path: filepath.Join(c.Path, name)}
+	err := os.Mkdir(child.Path, 0755)
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("failed to create cgroup %s: %w", child.Path, err)
+	}
+	return child, nil
+}
+
+// SetMemoryLimit sets the memory limit in KiB, swap is disabled so that the limit cannot be bypassed
+func (c *Cgroup) SetMemoryLimit(limit int64) error {
+	err := c.write("memory.max", strconv.FormatInt(limit*1024, 10))
+	if err != nil {
+		return err
+	}
+
+	err = c.write("memory.swap.max", "0")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// EnableControllers makes the controllers available to the children of the cgroup, it must not contain processes
+func (c *Cgroup) EnableControllers(controllers ...string) error {
+	for _, controller := range controllers {
+		err := c.write("cgroup.subtree_control", "+"+controller)
+		if err != nil {
+			return fmt.Errorf("failed to enable controller %s in cgroup %s: %w", controller, c.Path, err)
+		}
+	}
+	return nil
+}
+
+// SetCpuLimit sets the CPU bandwidth limit in cores
+func (c *Cgroup) SetCpuLimit(cores float64) error {
+	const period = 100000
+	return c.write("cpu.max", fmt.Sprintf("%d %d", int64(cores*period), period))
+}
+
+// SetCpuset restricts the cgroup to the given CPUs, in the cpuset list format such as 0-3,6
+func (c *Cgroup) SetCpuset(cpus string) error {
+	return c.write("cpuset.cpus", cpus)
+}
+
+// SetPidsLimit limits the number of processes and threads in the cgroup
+func (c *Cgroup) SetPidsLimit(limit int64) error {
+	return c.write("pids.max", strconv.FormatInt(limit, 10))
+}
+
+// OomKilled reports whether any process in the cgroup has been killed by the OOM killer
+func (c *Cgroup) OomKilled() (bool, error) {
+	count, err := c.readKey("memory.events", "oom_kill")
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Attach makes the command start inside the cgroup, the returned file must be closed once the command is started
+func (c *Cgroup) Attach(command *exec.Cmd) (*os.File, error) {
+	directory, err := os.Open(c.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	command.SysProcAttr.UseCgroupFD = true
+	command.SysProcAttr.CgroupFD = int(directory.Fd())
+	return directory, nil
+}
+
+// Destroy kills every remaining process in the cgroup and removes it
+func (c *Cgroup) Destroy() error {
+	err := c.write("cgroup.kill", "1")
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// the directory can only be removed once the killed processes are gone
+	for attempt := 0; attempt < 50; attempt++ {
+		err = os.Remove(c.Path)
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if !errors.Is(err, syscall.EBUSY) {
+			return err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+//go:build !linux
+
+package platform
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+var errCgroupUnsupported = errors.New("cgroups are not supported on this platform")
+
+type Cgroup struct {
+	Path string
+}
+
+func SetupCgroup(_ string) (*Cgroup, error) {
+	return nil, errCgroupUnsupported
+}
+
+func (c *Cgroup) Child(_ string) (*Cgroup, error) {
+	return nil, errCgroupUnsupported
+}
+
+func (c *Cgroup) SetMemoryLimit(_ int64) error {
+	return errCgroupUnsupported
+}
+
+func (c *Cgroup) OomKilled() (bool, error) {
+	return false, errCgroupUnsupported
+}
+
+func (c *Cgroup) EnableControllers(_ ...string) error {
+	return errCgroupUnsupported
+}
+
+func (c *Cgroup) SetCpuLimit(_ float64) error {
+	return errCgroupUnsupported
+}
+
+func (c *Cgroup) SetCpuset(_ string) error {
+	return errCgroupUnsupported
+}
+
+func (c *Cgroup) SetPidsLimit(_ int64) error {
+	return errCgroupUnsupported
+}
+
+func (c *Cgroup) Attach(_ *exec.Cmd) (*os.File, error) {
+	return nil, errCgroupUnsupported
+}
+
+func (c *Cgroup) Destroy() error {
+	return errCgroupUnsupported
+}
@@ -2,6 +2,62 @@
 
 package platform
 
-func ReportUsage() (*ResourceUsageReport, error) {
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+)
+
+func ReportUsage(_ *os.ProcessState) (*ResourceUsageReport, error) {
 	return nil, nil
 }
+
+func ReportExit(state *os.ProcessState) (int32, int32) {
+	return int32(state.ExitCode()), 0
+}
+
+func PrepareCommand(_ *exec.Cmd) {
+}
+
+func RunAs(_ *exec.Cmd, _ int, _ int) error {
+	return errors.New("running as another user is not supported on this platform")
+}
+
+func PeerUid(_ *net.UnixConn) (int, error) {
+	return 0, errors.New("peer credentials are not supported on this platform")
+}
+
+func KillProcessGroup(process *os.Process) error {
+	return process.Kill()
+}
+
+func LimitProcess(_ int, _ *ProcessLimits) error {
+	return nil
+}
+
+func SignalName(signal int32) string {
+	return fmt.Sprintf("SIG%d", signal)
+}
+
+func StrayProcesses() ([]int, error) {
+	return nil, errors.New("listing processes is not supported on this platform")
+}
+
+func KillStrayProcesses() error {
+	return errors.New("killing stray processes is not supported on this platform")
+}
+
+func MountProc() error {
+	return errors.New("mounting procfs is not supported on this platform")
+}
+
+// ProcessAlive cannot tell on this platform, the process is assumed to be alive
+func ProcessAlive(_ int) bool {
+	return true
+}
+
+func ReportSecurity() (*SecurityReport, error) {
+	return nil, errors.New("security report is not supported on this platform")
+}
@@ -2,13 +2,30 @@
 
 package platform
 
-import "syscall"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang.org/x/sys/unix"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
 
-func ReportUsage() (*ResourceUsageReport, error) {
-	data := syscall.Rusage{}
-	err := syscall.Getrusage(syscall.RUSAGE_CHILDREN, &data)
-	if err != nil {
-		return nil, err
+// ReportUsage reports the resources used by a single process, as collected by wait4 when it was reaped
+func ReportUsage(state *os.ProcessState) (*ResourceUsageReport, error) {
+	if state == nil {
+		return nil, errors.New("process has not exited")
+	}
+
+	data, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok || data == nil {
+		return nil, errors.New("resource usage is not available")
 	}
 
 	return &ResourceUsageReport{
@@ -19,3 +36,253 @@ func ReportUsage() (*ResourceUsageReport, error) {
 		MaxResidentSize: data.Maxrss,
 	}, nil
 }
+
+func ReportExit(state *os.ProcessState) (int32, int32) {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if ok && status.Signaled() {
+		return int32(state.ExitCode()), int32(status.Signal())
+	}
+	return int32(state.ExitCode()), 0
+}
+
+// PrepareCommand places the process in its own process group so that it can be killed along with its children
+func PrepareCommand(command *exec.Cmd) {
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	command.SysProcAttr.Setpgid = true
+}
+
+// RunAs makes the command run as another user without supplementary groups, a caller running as root
+// needs CAP_SETUID and CAP_SETGID for it and the command loses every capability in the switch
+func RunAs(command *exec.Cmd, uid int, gid int) error {
+	if command.SysProcAttr == nil {
+		command.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	command.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+	return nil
+}
+
+// PeerUid reports the user of the process on the other end of a unix socket connection
+func PeerUid(conn *net.UnixConn) (int, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+
+	var credentials *unix.Ucred
+	var credentialsErr error
+	err = raw.Control(func(fd uintptr) {
+		credentials, credentialsErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if credentialsErr != nil {
+		return 0, credentialsErr
+	}
+	return int(credentials.Uid), nil
+}
+
+func KillProcessGroup(process *os.Process) error {
+	err := syscall.Kill(-process.Pid, syscall.SIGKILL)
+	if err != nil && !errors.Is(err, syscall.ESRCH) {
+		return err
+	}
+	return nil
+}
+
+// LimitProcess applies resource limits to an already started process
+func LimitProcess(pid int, limits *ProcessLimits) error {
+	if limits.CpuTime > 0 {
+		// round up to the next second, the soft limit delivers SIGXCPU and the hard limit a SIGKILL
+		seconds := uint64((limits.CpuTime + time.Second - 1) / time.Second)
+		err := unix.Prlimit(pid, unix.RLIMIT_CPU, &unix.Rlimit{Cur: seconds, Max: seconds + 1}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	if limits.Memory > 0 {
+		// RLIMIT_AS would also count address space reserved but never used by runtimes such as Go's
+		bytes := uint64(limits.Memory) * 1024
+		err := unix.Prlimit(pid, unix.RLIMIT_DATA, &unix.Rlimit{Cur: bytes, Max: bytes}, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SignalName returns the conventional name of a signal such as SIGSEGV
+func SignalName(signal int32) string {
+	name := unix.SignalName(syscall.Signal(signal))
+	if name == "" {
+		return fmt.Sprintf("SIG%d", signal)
+	}
+	return name
+}
+
+// ProcessAlive reports whether a process with the pid exists, it may belong to another user
+func ProcessAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// StrayProcesses lists the live processes a sandbox reset has to kill, when the caller is the init
+// process of a container that is every other process, otherwise only the descendants of the caller
+func StrayProcesses() ([]int, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	parents := make(map[int]int)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		stat, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "stat"))
+		if err != nil {
+			continue // exited in the meantime
+		}
+
+		// the command name may contain spaces and parentheses, the other fields follow the last one
+		fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+		if len(fields) < 2 || fields[0] == "Z" {
+			continue
+		}
+		parent, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		parents[pid] = parent
+	}
+
+	self := os.Getpid()
+	stray := make([]int, 0)
+	for pid := range parents {
+		if pid == self {
+			continue
+		}
+
+		descendant := self == 1
+		for ancestor := parents[pid]; !descendant && ancestor > 1; ancestor = parents[ancestor] {
+			descendant = ancestor == self
+		}
+		if descendant {
+			stray = append(stray, pid)
+		}
+	}
+	return stray, nil
+}
+
+// KillStrayProcesses kills the processes reported by StrayProcesses and reaps the orphans
+// inherited by the caller when it is init, it must not run while commands are being waited on
+func KillStrayProcesses() error {
+	stray, err := StrayProcesses()
+	if err != nil {
+		return err
+	}
+
+	for _, pid := range stray {
+		err := syscall.Kill(pid, syscall.SIGKILL)
+		if err != nil && !errors.Is(err, syscall.ESRCH) {
+			return err
+		}
+	}
+
+	if os.Getpid() == 1 {
+		for {
+			pid, err := syscall.Wait4(-1, nil, syscall.WNOHANG, nil)
+			if err != nil || pid <= 0 {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// MountProc mounts a fresh procfs on /proc, it is meant for an init process in a new pid and mount namespace
+// so that /proc only shows the processes of the sandbox
+func MountProc() error {
+	return unix.Mount("proc", "/proc", "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, "")
+}
+
+// ReportSecurity collects the credentials, capabilities and limits of the calling process
+func ReportSecurity() (*SecurityReport, error) {
+	status, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	for _, line := range strings.Split(string(status), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if ok {
+			fields[key] = strings.TrimSpace(value)
+		}
+	}
+
+	report := &SecurityReport{
+		Uid:             os.Getuid(),
+		Gid:             os.Getgid(),
+		NoNewPrivileges: fields["NoNewPrivs"] == "1",
+		PidsLimit:       -1,
+	}
+
+	report.CapabilitiesEffective, err = strconv.ParseUint(fields["CapEff"], 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse effective capabilities: %w", err)
+	}
+
+	report.CapabilitiesBounding, err = strconv.ParseUint(fields["CapBnd"], 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bounding capabilities: %w", err)
+	}
+
+	// the field is missing on kernels built without seccomp
+	if fields["Seccomp"] != "" {
+		report.SeccompMode, err = strconv.Atoi(fields["Seccomp"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse seccomp mode: %w", err)
+		}
+	}
+
+	var root unix.Statfs_t
+	err = unix.Statfs("/", &root)
+	if err != nil {
+		return nil, err
+	}
+	report.ReadOnlyRoot = root.Flags&unix.ST_RDONLY != 0
+
+	var limit unix.Rlimit
+	err = unix.Getrlimit(unix.RLIMIT_NOFILE, &limit)
+	if err != nil {
+		return nil, err
+	}
+	report.FileLimit = limit.Cur
+
+	err = unix.Getrlimit(unix.RLIMIT_FSIZE, &limit)
+	if err != nil {
+		return nil, err
+	}
+	report.FileSizeLimit = limit.Cur
+
+	// cgroup v2 and v1 layouts as seen from inside a container
+	for _, path := range []string{"/sys/fs/cgroup/pids.max", "/sys/fs/cgroup/pids/pids.max"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		pids, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+		if err == nil {
+			report.PidsLimit = pids
+		}
+		break
+	}
+	return report, nil
+}
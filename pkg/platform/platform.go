@@ -1,5 +1,7 @@
 package platform
 
+import "time"
+
 type ResourceUsageReport struct {
 	TimeUser        int64
 	TimeSystem      int64
@@ -7,3 +9,23 @@ type ResourceUsageReport struct {
 	MajorFault      int64
 	MaxResidentSize int64
 }
+
+type ProcessLimits struct {
+	CpuTime time.Duration // CPU time limit, enforced at one second granularity
+	Memory  int64         // data segment limit in KiB, prefer a cgroup memory limit when available
+	Cgroup  *Cgroup       // cgroup to start the process in, if any
+}
+
+// SecurityReport describes the restrictions the calling process runs under
+type SecurityReport struct {
+	Uid                   int
+	Gid                   int
+	CapabilitiesEffective uint64
+	CapabilitiesBounding  uint64
+	NoNewPrivileges       bool
+	SeccompMode           int // 0 disabled, 1 strict, 2 filter
+	ReadOnlyRoot          bool
+	FileLimit             uint64
+	FileSizeLimit         uint64 // in bytes
+	PidsLimit             int64  // -1 if unlimited or unknown
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.1
+// 	protoc-gen-go v1.31.0
 // 	protoc        v3.6.1
 // source: rin.proto
 
@@ -20,6 +20,68 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type KillReason int32
+
+const (
+	KillReason_KILL_REASON_NONE         KillReason = 0
+	KillReason_KILL_REASON_TIME_LIMIT   KillReason = 1
+	KillReason_KILL_REASON_MEMORY_LIMIT KillReason = 2
+	KillReason_KILL_REASON_OUTPUT_LIMIT KillReason = 3
+)
+
+// Enum value maps for KillReason.
+var (
+	KillReason_name = map[int32]string{
+		0: "KILL_REASON_NONE",
+		1: "KILL_REASON_TIME_LIMIT",
+		2: "KILL_REASON_MEMORY_LIMIT",
+		3: "KILL_REASON_OUTPUT_LIMIT",
+	}
+	KillReason_value = map[string]int32{
+		"KILL_REASON_NONE":         0,
+		"KILL_REASON_TIME_LIMIT":   1,
+		"KILL_REASON_MEMORY_LIMIT": 2,
+		"KILL_REASON_OUTPUT_LIMIT": 3,
+	}
+)
+
+func (x KillReason) Enum() *KillReason {
+	p := new(KillReason)
+	*p = x
+	return p
+}
+
+func (x KillReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (KillReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_rin_proto_enumTypes[0].Descriptor()
+}
+
+func (KillReason) Type() protoreflect.EnumType {
+	return &file_rin_proto_enumTypes[0]
+}
+
+func (x KillReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Do not use.
+func (x *KillReason) UnmarshalJSON(b []byte) error {
+	num, err := protoimpl.X.UnmarshalJSONEnum(x.Descriptor(), b)
+	if err != nil {
+		return err
+	}
+	*x = KillReason(num)
+	return nil
+}
+
+// Deprecated: Use KillReason.Descriptor instead.
+func (KillReason) EnumDescriptor() ([]byte, []int) {
+	return file_rin_proto_rawDescGZIP(), []int{0}
+}
+
 type Empty struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -110,8 +172,13 @@ type TestContext struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Source      []byte `protobuf:"bytes,1,req,name=source" json:"source,omitempty"`
-	OptHashOnly *bool  `protobuf:"varint,2,opt,name=opt_hash_only,json=optHashOnly" json:"opt_hash_only,omitempty"`
+	Source         []byte `protobuf:"bytes,1,req,name=source" json:"source,omitempty"`
+	OptHashOnly    *bool  `protobuf:"varint,2,opt,name=opt_hash_only,json=optHashOnly" json:"opt_hash_only,omitempty"`
+	OptInteractive *bool  `protobuf:"varint,3,opt,name=opt_interactive,json=optInteractive" json:"opt_interactive,omitempty"`
+	TimeLimitCpu   *int64 `protobuf:"varint,4,opt,name=time_limit_cpu,json=timeLimitCpu" json:"time_limit_cpu,omitempty"`    // in ms
+	TimeLimitWall  *int64 `protobuf:"varint,5,opt,name=time_limit_wall,json=timeLimitWall" json:"time_limit_wall,omitempty"` // in ms
+	MemoryLimit    *int64 `protobuf:"varint,6,opt,name=memory_limit,json=memoryLimit" json:"memory_limit,omitempty"`         // in KiB
+	OutputLimit    *int64 `protobuf:"varint,7,opt,name=output_limit,json=outputLimit" json:"output_limit,omitempty"`         // in bytes
 }
 
 func (x *TestContext) Reset() {
@@ -160,16 +227,62 @@ func (x *TestContext) GetOptHashOnly() bool {
 	return false
 }
 
+func (x *TestContext) GetOptInteractive() bool {
+	if x != nil && x.OptInteractive != nil {
+		return *x.OptInteractive
+	}
+	return false
+}
+
+func (x *TestContext) GetTimeLimitCpu() int64 {
+	if x != nil && x.TimeLimitCpu != nil {
+		return *x.TimeLimitCpu
+	}
+	return 0
+}
+
+func (x *TestContext) GetTimeLimitWall() int64 {
+	if x != nil && x.TimeLimitWall != nil {
+		return *x.TimeLimitWall
+	}
+	return 0
+}
+
+func (x *TestContext) GetMemoryLimit() int64 {
+	if x != nil && x.MemoryLimit != nil {
+		return *x.MemoryLimit
+	}
+	return 0
+}
+
+func (x *TestContext) GetOutputLimit() int64 {
+	if x != nil && x.OutputLimit != nil {
+		return *x.OutputLimit
+	}
+	return 0
+}
+
 type TestResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Hash       []byte `protobuf:"bytes,1,req,name=hash" json:"hash,omitempty"`
-	Result     []byte `protobuf:"bytes,2,opt,name=result" json:"result,omitempty"`
-	TimeUser   *int64 `protobuf:"varint,3,opt,name=time_user,json=timeUser" json:"time_user,omitempty"`
-	TimeSystem *int64 `protobuf:"varint,4,opt,name=time_system,json=timeSystem" json:"time_system,omitempty"`
-	Memory     *int64 `protobuf:"varint,5,opt,name=memory" json:"memory,omitempty"`
+	Hash                []byte      `protobuf:"bytes,1,req,name=hash" json:"hash,omitempty"`
+	Result              []byte      `protobuf:"bytes,2,opt,name=result" json:"result,omitempty"`
+	TimeUser            *int64      `protobuf:"varint,3,opt,name=time_user,json=timeUser" json:"time_user,omitempty"`
+	TimeSystem          *int64      `protobuf:"varint,4,opt,name=time_system,json=timeSystem" json:"time_system,omitempty"`
+	Memory              *int64      `protobuf:"varint,5,opt,name=memory" json:"memory,omitempty"`
+	ExitCode            *int32      `protobuf:"varint,6,opt,name=exit_code,json=exitCode" json:"exit_code,omitempty"`
+	Signal              *int32      `protobuf:"varint,7,opt,name=signal" json:"signal,omitempty"`
+	InteractorExitCode  *int32      `protobuf:"varint,8,opt,name=interactor_exit_code,json=interactorExitCode" json:"interactor_exit_code,omitempty"`
+	InteractorMessage   []byte      `protobuf:"bytes,9,opt,name=interactor_message,json=interactorMessage" json:"interactor_message,omitempty"`
+	TimedOut            *bool       `protobuf:"varint,10,opt,name=timed_out,json=timedOut" json:"timed_out,omitempty"`
+	TimeWall            *int64      `protobuf:"varint,11,opt,name=time_wall,json=timeWall" json:"time_wall,omitempty"`
+	MemoryExceeded      *bool       `protobuf:"varint,12,opt,name=memory_exceeded,json=memoryExceeded" json:"memory_exceeded,omitempty"`
+	OutputLimitExceeded *bool       `protobuf:"varint,13,opt,name=output_limit_exceeded,json=outputLimitExceeded" json:"output_limit_exceeded,omitempty"`
+	Stderr              []byte      `protobuf:"bytes,14,opt,name=stderr" json:"stderr,omitempty"`
+	SignalName          *string     `protobuf:"bytes,15,opt,name=signal_name,json=signalName" json:"signal_name,omitempty"`
+	KillReason          *KillReason `protobuf:"varint,16,opt,name=kill_reason,json=killReason,enum=KillReason" json:"kill_reason,omitempty"`
 }
 
 func (x *TestResult) Reset() {
@@ -239,6 +352,193 @@ func (x *TestResult) GetMemory() int64 {
 	return 0
 }
 
+func (x *TestResult) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *TestResult) GetSignal() int32 {
+	if x != nil && x.Signal != nil {
+		return *x.Signal
+	}
+	return 0
+}
+
+func (x *TestResult) GetInteractorExitCode() int32 {
+	if x != nil && x.InteractorExitCode != nil {
+		return *x.InteractorExitCode
+	}
+	return 0
+}
+
+func (x *TestResult) GetInteractorMessage() []byte {
+	if x != nil {
+		return x.InteractorMessage
+	}
+	return nil
+}
+
+func (x *TestResult) GetTimedOut() bool {
+	if x != nil && x.TimedOut != nil {
+		return *x.TimedOut
+	}
+	return false
+}
+
+func (x *TestResult) GetTimeWall() int64 {
+	if x != nil && x.TimeWall != nil {
+		return *x.TimeWall
+	}
+	return 0
+}
+
+func (x *TestResult) GetMemoryExceeded() bool {
+	if x != nil && x.MemoryExceeded != nil {
+		return *x.MemoryExceeded
+	}
+	return false
+}
+
+func (x *TestResult) GetOutputLimitExceeded() bool {
+	if x != nil && x.OutputLimitExceeded != nil {
+		return *x.OutputLimitExceeded
+	}
+	return false
+}
+
+func (x *TestResult) GetStderr() []byte {
+	if x != nil {
+		return x.Stderr
+	}
+	return nil
+}
+
+func (x *TestResult) GetSignalName() string {
+	if x != nil && x.SignalName != nil {
+		return *x.SignalName
+	}
+	return ""
+}
+
+func (x *TestResult) GetKillReason() KillReason {
+	if x != nil && x.KillReason != nil {
+		return *x.KillReason
+	}
+	return KillReason_KILL_REASON_NONE
+}
+
+type TestBatchContext struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cases            []*TestContext `protobuf:"bytes,1,rep,name=cases" json:"cases,omitempty"`
+	OptStopOnFailure *bool          `protobuf:"varint,2,opt,name=opt_stop_on_failure,json=optStopOnFailure" json:"opt_stop_on_failure,omitempty"`
+}
+
+func (x *TestBatchContext) Reset() {
+	*x = TestBatchContext{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rin_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TestBatchContext) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestBatchContext) ProtoMessage() {}
+
+func (x *TestBatchContext) ProtoReflect() protoreflect.Message {
+	mi := &file_rin_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestBatchContext.ProtoReflect.Descriptor instead.
+func (*TestBatchContext) Descriptor() ([]byte, []int) {
+	return file_rin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TestBatchContext) GetCases() []*TestContext {
+	if x != nil {
+		return x.Cases
+	}
+	return nil
+}
+
+func (x *TestBatchContext) GetOptStopOnFailure() bool {
+	if x != nil && x.OptStopOnFailure != nil {
+		return *x.OptStopOnFailure
+	}
+	return false
+}
+
+type TestBatchResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index  *int32      `protobuf:"varint,1,req,name=index" json:"index,omitempty"`
+	Result *TestResult `protobuf:"bytes,2,req,name=result" json:"result,omitempty"`
+}
+
+func (x *TestBatchResult) Reset() {
+	*x = TestBatchResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rin_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TestBatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestBatchResult) ProtoMessage() {}
+
+func (x *TestBatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_rin_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestBatchResult.ProtoReflect.Descriptor instead.
+func (*TestBatchResult) Descriptor() ([]byte, []int) {
+	return file_rin_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TestBatchResult) GetIndex() int32 {
+	if x != nil && x.Index != nil {
+		return *x.Index
+	}
+	return 0
+}
+
+func (x *TestBatchResult) GetResult() *TestResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
 type CompileResult struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -251,7 +551,7 @@ type CompileResult struct {
 func (x *CompileResult) Reset() {
 	*x = CompileResult{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_rin_proto_msgTypes[4]
+		mi := &file_rin_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -264,7 +564,7 @@ func (x *CompileResult) String() string {
 func (*CompileResult) ProtoMessage() {}
 
 func (x *CompileResult) ProtoReflect() protoreflect.Message {
-	mi := &file_rin_proto_msgTypes[4]
+	mi := &file_rin_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -277,7 +577,7 @@ func (x *CompileResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CompileResult.ProtoReflect.Descriptor instead.
 func (*CompileResult) Descriptor() ([]byte, []int) {
-	return file_rin_proto_rawDescGZIP(), []int{4}
+	return file_rin_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *CompileResult) GetData() []byte {
@@ -294,40 +594,462 @@ func (x *CompileResult) GetSuccess() bool {
 	return false
 }
 
+type CheckContext struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Input    []byte `protobuf:"bytes,1,req,name=input" json:"input,omitempty"`
+	Expected []byte `protobuf:"bytes,2,req,name=expected" json:"expected,omitempty"`
+	Output   []byte `protobuf:"bytes,3,req,name=output" json:"output,omitempty"`
+}
+
+func (x *CheckContext) Reset() {
+	*x = CheckContext{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rin_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckContext) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckContext) ProtoMessage() {}
+
+func (x *CheckContext) ProtoReflect() protoreflect.Message {
+	mi := &file_rin_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckContext.ProtoReflect.Descriptor instead.
+func (*CheckContext) Descriptor() ([]byte, []int) {
+	return file_rin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CheckContext) GetInput() []byte {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *CheckContext) GetExpected() []byte {
+	if x != nil {
+		return x.Expected
+	}
+	return nil
+}
+
+func (x *CheckContext) GetOutput() []byte {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+type CheckResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ExitCode *int32 `protobuf:"varint,1,req,name=exit_code,json=exitCode" json:"exit_code,omitempty"`
+	Message  []byte `protobuf:"bytes,2,req,name=message" json:"message,omitempty"`
+}
+
+func (x *CheckResult) Reset() {
+	*x = CheckResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rin_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResult) ProtoMessage() {}
+
+func (x *CheckResult) ProtoReflect() protoreflect.Message {
+	mi := &file_rin_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResult.ProtoReflect.Descriptor instead.
+func (*CheckResult) Descriptor() ([]byte, []int) {
+	return file_rin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CheckResult) GetExitCode() int32 {
+	if x != nil && x.ExitCode != nil {
+		return *x.ExitCode
+	}
+	return 0
+}
+
+func (x *CheckResult) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type ResetResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Clean   *bool  `protobuf:"varint,1,req,name=clean" json:"clean,omitempty"`
+	Message []byte `protobuf:"bytes,2,req,name=message" json:"message,omitempty"` // what could not be cleaned up, empty if clean
+}
+
+func (x *ResetResult) Reset() {
+	*x = ResetResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rin_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResetResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetResult) ProtoMessage() {}
+
+func (x *ResetResult) ProtoReflect() protoreflect.Message {
+	mi := &file_rin_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetResult.ProtoReflect.Descriptor instead.
+func (*ResetResult) Descriptor() ([]byte, []int) {
+	return file_rin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ResetResult) GetClean() bool {
+	if x != nil && x.Clean != nil {
+		return *x.Clean
+	}
+	return false
+}
+
+func (x *ResetResult) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+type SecurityReport struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uid                   *int32  `protobuf:"varint,1,req,name=uid" json:"uid,omitempty"`
+	Gid                   *int32  `protobuf:"varint,2,req,name=gid" json:"gid,omitempty"`
+	CapabilitiesEffective *uint64 `protobuf:"varint,3,req,name=capabilities_effective,json=capabilitiesEffective" json:"capabilities_effective,omitempty"`
+	CapabilitiesBounding  *uint64 `protobuf:"varint,4,req,name=capabilities_bounding,json=capabilitiesBounding" json:"capabilities_bounding,omitempty"`
+	NoNewPrivileges       *bool   `protobuf:"varint,5,req,name=no_new_privileges,json=noNewPrivileges" json:"no_new_privileges,omitempty"`
+	SeccompMode           *int32  `protobuf:"varint,6,req,name=seccomp_mode,json=seccompMode" json:"seccomp_mode,omitempty"` // 0 disabled, 1 strict, 2 filter
+	ReadOnlyRoot          *bool   `protobuf:"varint,7,req,name=read_only_root,json=readOnlyRoot" json:"read_only_root,omitempty"`
+	FileLimit             *uint64 `protobuf:"varint,8,req,name=file_limit,json=fileLimit" json:"file_limit,omitempty"`
+	FileSizeLimit         *uint64 `protobuf:"varint,9,req,name=file_size_limit,json=fileSizeLimit" json:"file_size_limit,omitempty"` // in bytes
+	PidsLimit             *int64  `protobuf:"varint,10,req,name=pids_limit,json=pidsLimit" json:"pids_limit,omitempty"`              // -1 if unlimited or unknown
+	SandboxUid            *int32  `protobuf:"varint,11,opt,name=sandbox_uid,json=sandboxUid" json:"sandbox_uid,omitempty"`           // user submitted programs run as, -1 if they run as rin itself
+	SandboxGid            *int32  `protobuf:"varint,12,opt,name=sandbox_gid,json=sandboxGid" json:"sandbox_gid,omitempty"`
+}
+
+func (x *SecurityReport) Reset() {
+	*x = SecurityReport{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rin_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SecurityReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityReport) ProtoMessage() {}
+
+func (x *SecurityReport) ProtoReflect() protoreflect.Message {
+	mi := &file_rin_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityReport.ProtoReflect.Descriptor instead.
+func (*SecurityReport) Descriptor() ([]byte, []int) {
+	return file_rin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SecurityReport) GetUid() int32 {
+	if x != nil && x.Uid != nil {
+		return *x.Uid
+	}
+	return 0
+}
+
+func (x *SecurityReport) GetGid() int32 {
+	if x != nil && x.Gid != nil {
+		return *x.Gid
+	}
+	return 0
+}
+
+func (x *SecurityReport) GetCapabilitiesEffective() uint64 {
+	if x != nil && x.CapabilitiesEffective != nil {
+		return *x.CapabilitiesEffective
+	}
+	return 0
+}
+
+func (x *SecurityReport) GetCapabilitiesBounding() uint64 {
+	if x != nil && x.CapabilitiesBounding != nil {
+		return *x.CapabilitiesBounding
+	}
+	return 0
+}
+
+func (x *SecurityReport) GetNoNewPrivileges() bool {
+	if x != nil && x.NoNewPrivileges != nil {
+		return *x.NoNewPrivileges
+	}
+	return false
+}
+
+func (x *SecurityReport) GetSeccompMode() int32 {
+	if x != nil && x.SeccompMode != nil {
+		return *x.SeccompMode
+	}
+	return 0
+}
+
+func (x *SecurityReport) GetReadOnlyRoot() bool {
+	if x != nil && x.ReadOnlyRoot != nil {
+		return *x.ReadOnlyRoot
+	}
+	return false
+}
+
+func (x *SecurityReport) GetFileLimit() uint64 {
+	if x != nil && x.FileLimit != nil {
+		return *x.FileLimit
+	}
+	return 0
+}
+
+func (x *SecurityReport) GetFileSizeLimit() uint64 {
+	if x != nil && x.FileSizeLimit != nil {
+		return *x.FileSizeLimit
+	}
+	return 0
+}
+
+func (x *SecurityReport) GetPidsLimit() int64 {
+	if x != nil && x.PidsLimit != nil {
+		return *x.PidsLimit
+	}
+	return 0
+}
+
+func (x *SecurityReport) GetSandboxUid() int32 {
+	if x != nil && x.SandboxUid != nil {
+		return *x.SandboxUid
+	}
+	return 0
+}
+
+func (x *SecurityReport) GetSandboxGid() int32 {
+	if x != nil && x.SandboxGid != nil {
+		return *x.SandboxGid
+	}
+	return 0
+}
+
 var File_rin_proto protoreflect.FileDescriptor
 
 var file_rin_proto_rawDesc = []byte{
 	0x0a, 0x09, 0x72, 0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x07, 0x0a, 0x05, 0x45,
 	0x6d, 0x70, 0x74, 0x79, 0x22, 0x20, 0x0a, 0x06, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x16,
 	0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0c, 0x52, 0x06,
-	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22, 0x49, 0x0a, 0x0b, 0x54, 0x65, 0x73, 0x74, 0x43, 0x6f,
-	0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18,
-	0x01, 0x20, 0x02, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x22, 0x0a,
-	0x0d, 0x6f, 0x70, 0x74, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6f, 0x70, 0x74, 0x48, 0x61, 0x73, 0x68, 0x4f, 0x6e, 0x6c,
-	0x79, 0x22, 0x8e, 0x01, 0x0a, 0x0a, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
-	0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0c, 0x52, 0x04,
-	0x68, 0x61, 0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1b, 0x0a, 0x09,
-	0x74, 0x69, 0x6d, 0x65, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x08, 0x74, 0x69, 0x6d, 0x65, 0x55, 0x73, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x69, 0x6d,
-	0x65, 0x5f, 0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a,
-	0x74, 0x69, 0x6d, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65,
-	0x6d, 0x6f, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f,
-	0x72, 0x79, 0x22, 0x3d, 0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73,
-	0x75, 0x6c, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x02, 0x28,
-	0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65,
-	0x73, 0x73, 0x18, 0x02, 0x20, 0x02, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
-	0x73, 0x32, 0x88, 0x01, 0x0a, 0x03, 0x52, 0x69, 0x6e, 0x12, 0x18, 0x0a, 0x04, 0x50, 0x69, 0x6e,
-	0x67, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74,
-	0x79, 0x22, 0x00, 0x12, 0x24, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x12, 0x07,
-	0x2e, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x1a, 0x0e, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c,
-	0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x23, 0x0a, 0x04, 0x54, 0x65, 0x73,
-	0x74, 0x12, 0x0c, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x1a,
-	0x0b, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x1c,
-	0x0a, 0x08, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70,
-	0x74, 0x79, 0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x42, 0x0c, 0x5a, 0x0a,
-	0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x72, 0x69, 0x6e,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22, 0x86, 0x02, 0x0a, 0x0b, 0x54, 0x65, 0x73, 0x74, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x18, 0x01, 0x20, 0x02, 0x28, 0x0c, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x22,
+	0x0a, 0x0d, 0x6f, 0x70, 0x74, 0x5f, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x6f, 0x70, 0x74, 0x48, 0x61, 0x73, 0x68, 0x4f, 0x6e,
+	0x6c, 0x79, 0x12, 0x27, 0x0a, 0x0f, 0x6f, 0x70, 0x74, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x61,
+	0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x6f, 0x70, 0x74,
+	0x49, 0x6e, 0x74, 0x65, 0x72, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x24, 0x0a, 0x0e, 0x74,
+	0x69, 0x6d, 0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x5f, 0x63, 0x70, 0x75, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0c, 0x74, 0x69, 0x6d, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x43, 0x70,
+	0x75, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x5f,
+	0x77, 0x61, 0x6c, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x74, 0x69, 0x6d, 0x65,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x57, 0x61, 0x6c, 0x6c, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0b, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x21, 0x0a, 0x0c,
+	0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0b, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x22,
+	0xa2, 0x04, 0x0a, 0x0a, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0c, 0x52, 0x04, 0x68, 0x61,
+	0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x74,
+	0x69, 0x6d, 0x65, 0x55, 0x73, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x5f,
+	0x73, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x69,
+	0x6d, 0x65, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x6f,
+	0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x12, 0x1b, 0x0a, 0x09, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x6c, 0x12, 0x30, 0x0a, 0x14, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x61, 0x63,
+	0x74, 0x6f, 0x72, 0x5f, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x12, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x45,
+	0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x69, 0x6e, 0x74, 0x65, 0x72,
+	0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x11, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x64, 0x5f,
+	0x6f, 0x75, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x64,
+	0x4f, 0x75, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x77, 0x61, 0x6c, 0x6c,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x57, 0x61, 0x6c, 0x6c,
+	0x12, 0x27, 0x0a, 0x0f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x65, 0x78, 0x63, 0x65, 0x65,
+	0x64, 0x65, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x6d, 0x65, 0x6d, 0x6f, 0x72,
+	0x79, 0x45, 0x78, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x32, 0x0a, 0x15, 0x6f, 0x75, 0x74,
+	0x70, 0x75, 0x74, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x5f, 0x65, 0x78, 0x63, 0x65, 0x65, 0x64,
+	0x65, 0x64, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x45, 0x78, 0x63, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x74, 0x64, 0x65, 0x72, 0x72, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x73,
+	0x74, 0x64, 0x65, 0x72, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x6c, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x69, 0x67, 0x6e,
+	0x61, 0x6c, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x2c, 0x0a, 0x0b, 0x6b, 0x69, 0x6c, 0x6c, 0x5f, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0b, 0x2e, 0x4b, 0x69,
+	0x6c, 0x6c, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x0a, 0x6b, 0x69, 0x6c, 0x6c, 0x52, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x22, 0x65, 0x0a, 0x10, 0x54, 0x65, 0x73, 0x74, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x22, 0x0a, 0x05, 0x63, 0x61, 0x73, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x05, 0x63, 0x61, 0x73, 0x65, 0x73, 0x12, 0x2d, 0x0a, 0x13,
+	0x6f, 0x70, 0x74, 0x5f, 0x73, 0x74, 0x6f, 0x70, 0x5f, 0x6f, 0x6e, 0x5f, 0x66, 0x61, 0x69, 0x6c,
+	0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x6f, 0x70, 0x74, 0x53, 0x74,
+	0x6f, 0x70, 0x4f, 0x6e, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x22, 0x4c, 0x0a, 0x0f, 0x54,
+	0x65, 0x73, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x02, 0x28, 0x05, 0x52, 0x05, 0x69,
+	0x6e, 0x64, 0x65, 0x78, 0x12, 0x23, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02,
+	0x20, 0x02, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x3d, 0x0a, 0x0d, 0x43, 0x6f, 0x6d,
+	0x70, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x02, 0x28, 0x08, 0x52,
+	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0x58, 0x0a, 0x0c, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75,
+	0x74, 0x18, 0x01, 0x20, 0x02, 0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x02, 0x28, 0x0c,
+	0x52, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x18, 0x03, 0x20, 0x02, 0x28, 0x0c, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70,
+	0x75, 0x74, 0x22, 0x44, 0x0a, 0x0b, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x12, 0x1b, 0x0a, 0x09, 0x65, 0x78, 0x69, 0x74, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01,
+	0x20, 0x02, 0x28, 0x05, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x0c, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x3d, 0x0a, 0x0b, 0x52, 0x65, 0x73, 0x65,
+	0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x65, 0x61, 0x6e,
+	0x18, 0x01, 0x20, 0x02, 0x28, 0x08, 0x52, 0x05, 0x63, 0x6c, 0x65, 0x61, 0x6e, 0x12, 0x18, 0x0a,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x02, 0x28, 0x0c, 0x52, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xbd, 0x03, 0x0a, 0x0e, 0x53, 0x65, 0x63, 0x75,
+	0x72, 0x69, 0x74, 0x79, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x02, 0x28, 0x05, 0x52, 0x03, 0x75, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03,
+	0x67, 0x69, 0x64, 0x18, 0x02, 0x20, 0x02, 0x28, 0x05, 0x52, 0x03, 0x67, 0x69, 0x64, 0x12, 0x35,
+	0x0a, 0x16, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x5f, 0x65,
+	0x66, 0x66, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x03, 0x20, 0x02, 0x28, 0x04, 0x52, 0x15,
+	0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x45, 0x66, 0x66, 0x65,
+	0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x33, 0x0a, 0x15, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x69, 0x65, 0x73, 0x5f, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x18, 0x04,
+	0x20, 0x02, 0x28, 0x04, 0x52, 0x14, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69,
+	0x65, 0x73, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x12, 0x2a, 0x0a, 0x11, 0x6e, 0x6f,
+	0x5f, 0x6e, 0x65, 0x77, 0x5f, 0x70, 0x72, 0x69, 0x76, 0x69, 0x6c, 0x65, 0x67, 0x65, 0x73, 0x18,
+	0x05, 0x20, 0x02, 0x28, 0x08, 0x52, 0x0f, 0x6e, 0x6f, 0x4e, 0x65, 0x77, 0x50, 0x72, 0x69, 0x76,
+	0x69, 0x6c, 0x65, 0x67, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x63, 0x63, 0x6f, 0x6d,
+	0x70, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x02, 0x28, 0x05, 0x52, 0x0b, 0x73, 0x65,
+	0x63, 0x63, 0x6f, 0x6d, 0x70, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x24, 0x0a, 0x0e, 0x72, 0x65, 0x61,
+	0x64, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x5f, 0x72, 0x6f, 0x6f, 0x74, 0x18, 0x07, 0x20, 0x02, 0x28,
+	0x08, 0x52, 0x0c, 0x72, 0x65, 0x61, 0x64, 0x4f, 0x6e, 0x6c, 0x79, 0x52, 0x6f, 0x6f, 0x74, 0x12,
+	0x1d, 0x0a, 0x0a, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x08, 0x20,
+	0x02, 0x28, 0x04, 0x52, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x26,
+	0x0a, 0x0f, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69,
+	0x74, 0x18, 0x09, 0x20, 0x02, 0x28, 0x04, 0x52, 0x0d, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x69, 0x7a,
+	0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x69, 0x64, 0x73, 0x5f, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x18, 0x0a, 0x20, 0x02, 0x28, 0x03, 0x52, 0x09, 0x70, 0x69, 0x64, 0x73,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x5f, 0x75, 0x69, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x55, 0x69, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x5f, 0x67, 0x69, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x47, 0x69, 0x64, 0x2a, 0x7a, 0x0a, 0x0a, 0x4b, 0x69, 0x6c, 0x6c, 0x52,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x10, 0x4b, 0x49, 0x4c, 0x4c, 0x5f, 0x52, 0x45,
+	0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x4b,
+	0x49, 0x4c, 0x4c, 0x5f, 0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x5f,
+	0x4c, 0x49, 0x4d, 0x49, 0x54, 0x10, 0x01, 0x12, 0x1c, 0x0a, 0x18, 0x4b, 0x49, 0x4c, 0x4c, 0x5f,
+	0x52, 0x45, 0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x4d, 0x45, 0x4d, 0x4f, 0x52, 0x59, 0x5f, 0x4c, 0x49,
+	0x4d, 0x49, 0x54, 0x10, 0x02, 0x12, 0x1c, 0x0a, 0x18, 0x4b, 0x49, 0x4c, 0x4c, 0x5f, 0x52, 0x45,
+	0x41, 0x53, 0x4f, 0x4e, 0x5f, 0x4f, 0x55, 0x54, 0x50, 0x55, 0x54, 0x5f, 0x4c, 0x49, 0x4d, 0x49,
+	0x54, 0x10, 0x03, 0x32, 0x89, 0x03, 0x0a, 0x03, 0x52, 0x69, 0x6e, 0x12, 0x18, 0x0a, 0x04, 0x50,
+	0x69, 0x6e, 0x67, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x24, 0x0a, 0x07, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65,
+	0x12, 0x07, 0x2e, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x1a, 0x0e, 0x2e, 0x43, 0x6f, 0x6d, 0x70,
+	0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x23, 0x0a, 0x04, 0x54,
+	0x65, 0x73, 0x74, 0x12, 0x0c, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x1a, 0x0b, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00,
+	0x12, 0x2b, 0x0a, 0x0e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x65, 0x72, 0x12, 0x07, 0x2e, 0x53, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x1a, 0x0e, 0x2e, 0x43, 0x6f,
+	0x6d, 0x70, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x26, 0x0a,
+	0x05, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x0d, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x1a, 0x0c, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x2e, 0x0a, 0x11, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65,
+	0x49, 0x6e, 0x74, 0x65, 0x72, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x07, 0x2e, 0x53, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x1a, 0x0e, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x69, 0x6c, 0x65, 0x52, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x34, 0x0a, 0x09, 0x54, 0x65, 0x73, 0x74, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x12, 0x11, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x42, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x78, 0x74, 0x1a, 0x10, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x1f, 0x0a, 0x05, 0x52,
+	0x65, 0x73, 0x65, 0x74, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0c, 0x2e, 0x52,
+	0x65, 0x73, 0x65, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x00, 0x12, 0x23, 0x0a, 0x06,
+	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x0f,
+	0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x22,
+	0x00, 0x12, 0x1c, 0x0a, 0x08, 0x53, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x06, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x06, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x42,
+	0x0c, 0x5a, 0x0a, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x72, 0x69, 0x6e,
 }
 
 var (
@@ -342,28 +1064,51 @@ func file_rin_proto_rawDescGZIP() []byte {
 	return file_rin_proto_rawDescData
 }
 
-var file_rin_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_rin_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_rin_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_rin_proto_goTypes = []interface{}{
-	(*Empty)(nil),         // 0: Empty
-	(*Source)(nil),        // 1: Source
-	(*TestContext)(nil),   // 2: TestContext
-	(*TestResult)(nil),    // 3: TestResult
-	(*CompileResult)(nil), // 4: CompileResult
+	(KillReason)(0),          // 0: KillReason
+	(*Empty)(nil),            // 1: Empty
+	(*Source)(nil),           // 2: Source
+	(*TestContext)(nil),      // 3: TestContext
+	(*TestResult)(nil),       // 4: TestResult
+	(*TestBatchContext)(nil), // 5: TestBatchContext
+	(*TestBatchResult)(nil),  // 6: TestBatchResult
+	(*CompileResult)(nil),    // 7: CompileResult
+	(*CheckContext)(nil),     // 8: CheckContext
+	(*CheckResult)(nil),      // 9: CheckResult
+	(*ResetResult)(nil),      // 10: ResetResult
+	(*SecurityReport)(nil),   // 11: SecurityReport
 }
 var file_rin_proto_depIdxs = []int32{
-	0, // 0: Rin.Ping:input_type -> Empty
-	1, // 1: Rin.Compile:input_type -> Source
-	2, // 2: Rin.Test:input_type -> TestContext
-	0, // 3: Rin.Shutdown:input_type -> Empty
-	0, // 4: Rin.Ping:output_type -> Empty
-	4, // 5: Rin.Compile:output_type -> CompileResult
-	3, // 6: Rin.Test:output_type -> TestResult
-	0, // 7: Rin.Shutdown:output_type -> Empty
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: TestResult.kill_reason:type_name -> KillReason
+	3,  // 1: TestBatchContext.cases:type_name -> TestContext
+	4,  // 2: TestBatchResult.result:type_name -> TestResult
+	1,  // 3: Rin.Ping:input_type -> Empty
+	2,  // 4: Rin.Compile:input_type -> Source
+	3,  // 5: Rin.Test:input_type -> TestContext
+	2,  // 6: Rin.CompileChecker:input_type -> Source
+	8,  // 7: Rin.Check:input_type -> CheckContext
+	2,  // 8: Rin.CompileInteractor:input_type -> Source
+	5,  // 9: Rin.TestBatch:input_type -> TestBatchContext
+	1,  // 10: Rin.Reset:input_type -> Empty
+	1,  // 11: Rin.Report:input_type -> Empty
+	1,  // 12: Rin.Shutdown:input_type -> Empty
+	1,  // 13: Rin.Ping:output_type -> Empty
+	7,  // 14: Rin.Compile:output_type -> CompileResult
+	4,  // 15: Rin.Test:output_type -> TestResult
+	7,  // 16: Rin.CompileChecker:output_type -> CompileResult
+	9,  // 17: Rin.Check:output_type -> CheckResult
+	7,  // 18: Rin.CompileInteractor:output_type -> CompileResult
+	6,  // 19: Rin.TestBatch:output_type -> TestBatchResult
+	10, // 20: Rin.Reset:output_type -> ResetResult
+	11, // 21: Rin.Report:output_type -> SecurityReport
+	1,  // 22: Rin.Shutdown:output_type -> Empty
+	13, // [13:23] is the sub-list for method output_type
+	3,  // [3:13] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
 }
 
 func init() { file_rin_proto_init() }
@@ -421,6 +1166,30 @@ func file_rin_proto_init() {
 			}
 		}
 		file_rin_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TestBatchContext); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rin_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TestBatchResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rin_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CompileResult); i {
 			case 0:
 				return &v.state
@@ -432,19 +1201,68 @@ func file_rin_proto_init() {
 				return nil
 			}
 		}
+		file_rin_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckContext); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rin_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rin_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResetResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rin_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SecurityReport); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_rin_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   5,
+			NumEnums:      1,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_rin_proto_goTypes,
 		DependencyIndexes: file_rin_proto_depIdxs,
+		EnumInfos:         file_rin_proto_enumTypes,
 		MessageInfos:      file_rin_proto_msgTypes,
 	}.Build()
 	File_rin_proto = out.File
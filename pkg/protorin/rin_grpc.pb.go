@@ -25,6 +25,12 @@ type RinClient interface {
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	Compile(ctx context.Context, in *Source, opts ...grpc.CallOption) (*CompileResult, error)
 	Test(ctx context.Context, in *TestContext, opts ...grpc.CallOption) (*TestResult, error)
+	CompileChecker(ctx context.Context, in *Source, opts ...grpc.CallOption) (*CompileResult, error)
+	Check(ctx context.Context, in *CheckContext, opts ...grpc.CallOption) (*CheckResult, error)
+	CompileInteractor(ctx context.Context, in *Source, opts ...grpc.CallOption) (*CompileResult, error)
+	TestBatch(ctx context.Context, in *TestBatchContext, opts ...grpc.CallOption) (Rin_TestBatchClient, error)
+	Reset(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResetResult, error)
+	Report(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SecurityReport, error)
 	Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 }
 
@@ -63,6 +69,83 @@ func (c *rinClient) Test(ctx context.Context, in *TestContext, opts ...grpc.Call
 	return out, nil
 }
 
+func (c *rinClient) CompileChecker(ctx context.Context, in *Source, opts ...grpc.CallOption) (*CompileResult, error) {
+	out := new(CompileResult)
+	err := c.cc.Invoke(ctx, "/Rin/CompileChecker", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rinClient) Check(ctx context.Context, in *CheckContext, opts ...grpc.CallOption) (*CheckResult, error) {
+	out := new(CheckResult)
+	err := c.cc.Invoke(ctx, "/Rin/Check", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rinClient) CompileInteractor(ctx context.Context, in *Source, opts ...grpc.CallOption) (*CompileResult, error) {
+	out := new(CompileResult)
+	err := c.cc.Invoke(ctx, "/Rin/CompileInteractor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rinClient) TestBatch(ctx context.Context, in *TestBatchContext, opts ...grpc.CallOption) (Rin_TestBatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Rin_ServiceDesc.Streams[0], "/Rin/TestBatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rinTestBatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Rin_TestBatchClient interface {
+	Recv() (*TestBatchResult, error)
+	grpc.ClientStream
+}
+
+type rinTestBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *rinTestBatchClient) Recv() (*TestBatchResult, error) {
+	m := new(TestBatchResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *rinClient) Reset(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ResetResult, error) {
+	out := new(ResetResult)
+	err := c.cc.Invoke(ctx, "/Rin/Reset", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rinClient) Report(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SecurityReport, error) {
+	out := new(SecurityReport)
+	err := c.cc.Invoke(ctx, "/Rin/Report", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *rinClient) Shutdown(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/Rin/Shutdown", in, out, opts...)
@@ -79,6 +162,12 @@ type RinServer interface {
 	Ping(context.Context, *Empty) (*Empty, error)
 	Compile(context.Context, *Source) (*CompileResult, error)
 	Test(context.Context, *TestContext) (*TestResult, error)
+	CompileChecker(context.Context, *Source) (*CompileResult, error)
+	Check(context.Context, *CheckContext) (*CheckResult, error)
+	CompileInteractor(context.Context, *Source) (*CompileResult, error)
+	TestBatch(*TestBatchContext, Rin_TestBatchServer) error
+	Reset(context.Context, *Empty) (*ResetResult, error)
+	Report(context.Context, *Empty) (*SecurityReport, error)
 	Shutdown(context.Context, *Empty) (*Empty, error)
 	mustEmbedUnimplementedRinServer()
 }
@@ -96,6 +185,24 @@ func (UnimplementedRinServer) Compile(context.Context, *Source) (*CompileResult,
 func (UnimplementedRinServer) Test(context.Context, *TestContext) (*TestResult, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Test not implemented")
 }
+func (UnimplementedRinServer) CompileChecker(context.Context, *Source) (*CompileResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompileChecker not implemented")
+}
+func (UnimplementedRinServer) Check(context.Context, *CheckContext) (*CheckResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
+}
+func (UnimplementedRinServer) CompileInteractor(context.Context, *Source) (*CompileResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompileInteractor not implemented")
+}
+func (UnimplementedRinServer) TestBatch(*TestBatchContext, Rin_TestBatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method TestBatch not implemented")
+}
+func (UnimplementedRinServer) Reset(context.Context, *Empty) (*ResetResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reset not implemented")
+}
+func (UnimplementedRinServer) Report(context.Context, *Empty) (*SecurityReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Report not implemented")
+}
 func (UnimplementedRinServer) Shutdown(context.Context, *Empty) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Shutdown not implemented")
 }
@@ -166,6 +273,117 @@ func _Rin_Test_Handler(srv interface{}, ctx context.Context, dec func(interface{
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Rin_CompileChecker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Source)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RinServer).CompileChecker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Rin/CompileChecker",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RinServer).CompileChecker(ctx, req.(*Source))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rin_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckContext)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RinServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Rin/Check",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RinServer).Check(ctx, req.(*CheckContext))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rin_CompileInteractor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Source)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RinServer).CompileInteractor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Rin/CompileInteractor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RinServer).CompileInteractor(ctx, req.(*Source))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rin_TestBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TestBatchContext)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RinServer).TestBatch(m, &rinTestBatchServer{stream})
+}
+
+type Rin_TestBatchServer interface {
+	Send(*TestBatchResult) error
+	grpc.ServerStream
+}
+
+type rinTestBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *rinTestBatchServer) Send(m *TestBatchResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Rin_Reset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RinServer).Reset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Rin/Reset",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RinServer).Reset(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Rin_Report_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RinServer).Report(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Rin/Report",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RinServer).Report(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Rin_Shutdown_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -203,11 +421,37 @@ var Rin_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Test",
 			Handler:    _Rin_Test_Handler,
 		},
+		{
+			MethodName: "CompileChecker",
+			Handler:    _Rin_CompileChecker_Handler,
+		},
+		{
+			MethodName: "Check",
+			Handler:    _Rin_Check_Handler,
+		},
+		{
+			MethodName: "CompileInteractor",
+			Handler:    _Rin_CompileInteractor_Handler,
+		},
+		{
+			MethodName: "Reset",
+			Handler:    _Rin_Reset_Handler,
+		},
+		{
+			MethodName: "Report",
+			Handler:    _Rin_Report_Handler,
+		},
 		{
 			MethodName: "Shutdown",
 			Handler:    _Rin_Shutdown_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TestBatch",
+			Handler:       _Rin_TestBatch_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "rin.proto",
 }
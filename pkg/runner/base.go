@@ -6,18 +6,87 @@ import (
 	"errors"
 	"google.golang.org/grpc"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type ContainerInfo struct {
 	ContainerId string
-	Port        int
+	Port        int    // host port rin is published on, assigned by the engine, 0 if not published
+	Address     string // gRPC target of rin, such as 127.0.0.1:8888 or unix:///path/to/rin.sock
+	Cpu         int    // CPU dedicated to the container, -1 if not pinned
 
 	Request         ContainerStartRequest
 	GrpcConnection  *grpc.ClientConn
 	GrpcClient      protorin.RinClient
+	Template        *ContainerTemplate
+	Uses            int  // submissions served so far
+	Isolated        bool // cut from the network it had for compilation
+	Verified        bool // the security profile has been confirmed by rin
 	WaitForShutdown bool
+	WaitForReset    bool // released by its submission, to be reset and put back in the pool
 	Lock            sync.Mutex
+
+	// set by the runner when the container stops on its own, such as when it is killed by the kernel
+	exited    atomic.Bool
+	oomKilled atomic.Bool
+	// processes killed by the OOM killer since the container was last reset, rin is not necessarily one of them
+	oomKills atomic.Int64
+}
+
+// MarkExited records that the container stopped without being asked to
+func (c *ContainerInfo) MarkExited(oomKilled bool) {
+	// the cause is stored first so that it is visible to anyone who sees the container as exited
+	if oomKilled {
+		c.oomKilled.Store(true)
+	}
+	c.exited.Store(true)
+}
+
+// MarkOomKilled records that the OOM killer killed a process of the container, which may still be running
+// if it was not rin
+func (c *ContainerInfo) MarkOomKilled() {
+	c.oomKills.Add(1)
+}
+
+// Exited reports whether the container stopped without being asked to
+func (c *ContainerInfo) Exited() bool {
+	return c.exited.Load()
+}
+
+// OomKilled reports whether the container exited because the OOM killer killed rin
+func (c *ContainerInfo) OomKilled() bool {
+	return c.oomKilled.Load()
+}
+
+// OomKills counts the processes of the container killed by the OOM killer since it was last reset
+func (c *ContainerInfo) OomKills() int64 {
+	return c.oomKills.Load()
+}
+
+// ResetOomKills forgets the OOM kills of the previous submission
+func (c *ContainerInfo) ResetOomKills() {
+	c.oomKills.Store(0)
+}
+
+// WaitOomKill waits for the runner to report an OOM kill after the given count, the report may arrive
+// after rin has already reported the killed process
+func (c *ContainerInfo) WaitOomKill(since int64, timeout time.Duration) bool {
+	threshold := time.Now().Add(timeout)
+	for c.OomKills() <= since && time.Now().Before(threshold) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return c.OomKills() > since
+}
+
+// WaitExited waits for the runner to report the container as exited, the report may arrive
+// after a call to rin has already failed because of it
+func (c *ContainerInfo) WaitExited(timeout time.Duration) bool {
+	threshold := time.Now().Add(timeout)
+	for !c.Exited() && time.Now().Before(threshold) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	return c.Exited()
 }
 
 func (c *ContainerInfo) Wait(timeLimit time.Duration) (bool, error) {
@@ -44,16 +113,51 @@ type ContainerStartRequest struct {
 	Slot         int
 	Image        string
 	PortInternal int
-	PortExternal int
+	CpusetCpus   string // CPUs the container is pinned to, unpinned if empty
+	Rootfs       string
+	WorkDir      string
+	Env          []string
+	Network      string
+	Security     *SecurityProfile // hardening of the container, none if nil
 }
 
 type Runner interface {
 	Start(ctx context.Context, request *ContainerStartRequest) (*ContainerInfo, error)
 	Stop(ctx context.Context, info *ContainerInfo) error
+	// Isolate cuts the container from the network it was given for compilation
+	Isolate(ctx context.Context, info *ContainerInfo) error
+	// PrepareImage makes sure the template can be started and returns the pinned image to start it from
+	PrepareImage(ctx context.Context, template *ContainerTemplate) (string, error)
+	// Reap removes containers left behind by dead instances of the core, and the untracked ones older than maxAge
+	Reap(ctx context.Context, maxAge time.Duration) error
+	CleanUp(ctx context.Context) error
+	// PinsCpus reports whether the CPUs of a start request are applied, the service only dedicates CPUs if so
+	PinsCpus() bool
 }
 
+const (
+	NetworkNone    = "none"    // no network at any time
+	NetworkCompile = "compile" // egress while compiling, for example to download Go modules
+	NetworkBridge  = "bridge"  // egress at all times, only meant for trusted code
+)
+
 type ContainerTemplate struct {
 	Id           string `json:"id"`
 	Image        string `json:"image"`
 	PortInternal int    `json:"portInternal"`
+	Parallelism  int    `json:"parallelism"`  // number of containers a submission may be sharded across
+	PoolMin      int    `json:"poolMin"`      // containers kept started and ready, no pool if 0
+	PoolMax      int    `json:"poolMax"`      // idle containers kept at most, extra ones are shut down
+	MaxUses      int    `json:"maxUses"`      // submissions a container serves before being replaced, no reuse if 1 or less
+	Network      string `json:"network"`      // none, compile or bridge, none if empty
+	Pull         bool   `json:"pull"`         // pull the image at startup if it is missing
+	ImageTarball string `json:"imageTarball"` // archive the image is loaded from at startup if it is missing, over pulling it
+	PinnedImage  string `json:"-"`            // id of the image resolved at startup, started in place of Image
+
+	// used by the native runner in place of the image, the rootfs is an extracted copy of the image
+	Rootfs  string   `json:"rootfs"`
+	WorkDir string   `json:"workDir"`
+	Env     []string `json:"env"` // KEY=VALUE entries, the image configuration is not available to the native runner
+
+	Security *SecurityProfile `json:"security"` // DefaultSecurityProfile if not set
 }
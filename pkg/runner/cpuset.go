@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+var ErrNoFreeCpu = errors.New("no free CPU to pin a container to")
+
+// ParseCpuList reads a list of CPUs in the cpuset format such as 0-3,6, every CPU if the list is empty
+func ParseCpuList(list string) ([]int, error) {
+	if strings.TrimSpace(list) == "" {
+		cpus := make([]int, runtime.NumCPU())
+		for i := range cpus {
+			cpus[i] = i
+		}
+		return cpus, nil
+	}
+
+	seen := make(map[int]bool)
+	cpus := make([]int, 0)
+	for _, part := range strings.Split(list, ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU list %s: %w", list, err)
+		}
+
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(last)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CPU list %s: %w", list, err)
+			}
+		}
+
+		if start < 0 || end < start {
+			return nil, fmt.Errorf("invalid CPU range %s", part)
+		}
+
+		for cpu := start; cpu <= end; cpu++ {
+			if !seen[cpu] {
+				seen[cpu] = true
+				cpus = append(cpus, cpu)
+			}
+		}
+	}
+	return cpus, nil
+}
+
+// takeCpu dedicates a free CPU to a slot, it must be called with the lock held
+func (s *Service) takeCpu(slot int) (int, error) {
+	for _, cpu := range s.Cpus {
+		if _, used := s.cpuOwners[cpu]; !used {
+			s.cpuOwners[cpu] = slot
+			return cpu, nil
+		}
+	}
+	return -1, ErrNoFreeCpu
+}
+
+// releaseCpu makes the CPU of a container available again, it must be called with the lock held
+func (s *Service) releaseCpu(cpu int) {
+	if cpu >= 0 {
+		delete(s.cpuOwners, cpu)
+	}
+}
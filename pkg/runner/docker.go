@@ -5,66 +5,247 @@ import (
 	"fmt"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 	"log"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 )
 
 const Prefix = "runner-"
 
+// ContainerSocketDirectory is where the socket directory of a container is mounted when rin listens on a unix socket
+const ContainerSocketDirectory = "/run/rin"
+
+// InternalNetwork is the network without egress through which the core reaches rin over TCP
+const InternalNetwork = "gradingcore-internal"
+
+// AbortStartTimeout bounds the removal of what a failed start left behind
+const AbortStartTimeout = 10 * time.Second
+
+// InterContainerOption is the bridge driver option allowing traffic between the containers of a network
+const InterContainerOption = "com.docker.network.bridge.enable_icc"
+
 type DockerRunner struct {
 	Client          *client.Client
+	Instance        string // labels the containers of this core, other cores may share the engine
+	Hostname        string // host of this core, the liveness of an instance can only be checked on its host
 	CpuHardLimit    float64
 	MemoryHardLimit int64
+	BindAddress     string // host address the port of rin is published on
+	SocketDirectory string // holds a directory per container in which rin listens on a unix socket, TCP is used if empty
+	DefaultNetwork  string // network of the engine providing egress
+
+	// limits the engine is able to enforce, rootless engines depend on the cgroup controllers delegated to them
+	LimitMemory bool
+	LimitCpu    bool
+	LimitCpuset bool
+
+	watched   map[string]*ContainerInfo // started containers by id, their death is reported by the events of the engine
+	starting  map[string]bool           // names of the containers being created or started, not watched yet
+	watchLock sync.Mutex
 }
 
-func NewDockerRunner(cpuHardLimit float64, memoryHardLimit int64) (*DockerRunner, error) {
+func NewDockerRunner(cpuHardLimit float64, memoryHardLimit int64, socketDirectory string) (*DockerRunner, error) {
 	dockerClient, err := client.NewClientWithOpts(client.FromEnv)
 	if err != nil {
 		return nil, err
 	}
+	return newDockerRunner(dockerClient, cpuHardLimit, memoryHardLimit, socketDirectory, "bridge", true, true, true)
+}
+
+// newDockerRunner sets up a runner on a client of the Docker API, given the network providing egress and
+// the limits the engine is able to enforce
+func newDockerRunner(engineClient *client.Client, cpuHardLimit float64, memoryHardLimit int64, socketDirectory string,
+	defaultNetwork string, limitMemory bool, limitCpu bool, limitCpuset bool) (*DockerRunner, error) {
+	socketDirectory, err := prepareSocketDirectory(socketDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := newInstanceId()
+	if err != nil {
+		return nil, err
+	}
 
-	return &DockerRunner{
-		Client:          dockerClient,
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	runner := &DockerRunner{
+		Client:          engineClient,
+		Instance:        instance,
+		Hostname:        hostname,
 		CpuHardLimit:    cpuHardLimit,
 		MemoryHardLimit: memoryHardLimit,
-	}, nil
+		// the core is the only one connecting to rin
+		BindAddress:     "127.0.0.1",
+		SocketDirectory: socketDirectory,
+		DefaultNetwork:  defaultNetwork,
+		LimitMemory:     limitMemory,
+		LimitCpu:        limitCpu,
+		LimitCpuset:     limitCpuset,
+		watched:         make(map[string]*ContainerInfo),
+		starting:        make(map[string]bool),
+	}
+
+	err = runner.prepareInternalNetwork(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	go runner.watchEvents(context.Background())
+	return runner, nil
+}
+
+// PinsCpus reports whether the engine applies the CPUs of a start request
+func (r *DockerRunner) PinsCpus() bool {
+	return r.LimitCpuset
+}
+
+// prepareInternalNetwork creates the internal network used by the TCP transport if it does not exist yet
+func (r *DockerRunner) prepareInternalNetwork(ctx context.Context) error {
+	if r.SocketDirectory != "" {
+		return nil
+	}
+
+	networks, err := r.Client.NetworkList(ctx, types.NetworkListOptions{Filters: filters.NewArgs(filters.Arg("name", InternalNetwork))})
+	if err != nil {
+		return err
+	}
+
+	for _, network := range networks {
+		if network.Name == InternalNetwork {
+			if !network.Internal {
+				return fmt.Errorf("network %s exists but is not internal", InternalNetwork)
+			}
+			if network.Options[InterContainerOption] != "false" {
+				return fmt.Errorf("network %s exists but lets containers reach each other", InternalNetwork)
+			}
+			return nil
+		}
+	}
+
+	_, err = r.Client.NetworkCreate(ctx, InternalNetwork, types.NetworkCreate{
+		CheckDuplicate: true,
+		Driver:         "bridge",
+		Internal:       true,
+		// the core reaches every sandbox through the bridge, a sandbox must not reach the rin of another
+		Options: map[string]string{InterContainerOption: "false"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create network %s: %w", InternalNetwork, err)
+	}
+	return nil
 }
 
-func (r *DockerRunner) createPortConfig(port int, portExt int) (nat.PortSet, nat.PortMap) {
-	portString := strconv.FormatInt(int64(port), 10)
-	portStringExt := strconv.FormatInt(int64(portExt), 10)
-	portName := nat.Port(portString + "/tcp")
+// createPortConfig publishes the port of rin on a host port chosen by the engine, it is read back once started
+func (r *DockerRunner) createPortConfig(port int) (nat.PortSet, nat.PortMap) {
+	portName := nat.Port(strconv.Itoa(port) + "/tcp")
 	portSet := nat.PortSet{
 		portName: struct{}{},
 	}
 
-	portSet[portName] = struct{}{}
 	portBinding := nat.PortMap{
-		portName: []nat.PortBinding{{HostIP: "0.0.0.0", HostPort: portStringExt}},
+		portName: []nat.PortBinding{{HostIP: r.BindAddress, HostPort: ""}},
 	}
 
 	return portSet, portBinding
 }
 
-func (r *DockerRunner) Start(ctx context.Context, request *ContainerStartRequest) (*ContainerInfo, error) {
-	slot := request.Slot
-	slotName := fmt.Sprintf("%s%d", Prefix, slot)
-	portSet, portMap := r.createPortConfig(request.PortInternal, request.PortExternal)
+func (r *DockerRunner) Start(ctx context.Context, request *ContainerStartRequest) (_ *ContainerInfo, err error) {
+	slotName := r.containerName(request.Slot)
+	containerId := ""
+	// the container is not watched until it runs, the reaper must not take it for an orphan meanwhile
+	r.markStarting(slotName)
+	defer func() {
+		if err != nil {
+			r.abortStart(containerId, slotName)
+		}
+		r.unmarkStarting(slotName)
+	}()
+
 	cfg := container.Config{
-		Hostname:     slotName,
-		Image:        request.Image,
-		ExposedPorts: portSet,
+		Hostname: slotName,
+		Image:    request.Image,
+		Labels:   r.labels(),
 	}
 	hostConfig := container.HostConfig{
-		Privileged:   false,
-		PortBindings: portMap,
-		Resources: container.Resources{
-			Memory:   r.MemoryHardLimit * 1024,
-			NanoCPUs: int64(r.CpuHardLimit * 1e9),
-		},
+		Privileged: false,
+	}
+
+	var address string
+	if r.SocketDirectory != "" {
+		socketDirectory := filepath.Join(r.SocketDirectory, slotName)
+		err := os.RemoveAll(socketDirectory)
+		if err != nil {
+			return nil, err
+		}
+
+		err = os.Mkdir(socketDirectory, 0700)
+		if err != nil {
+			return nil, err
+		}
+
+		// rin running as an unprivileged user has to create its socket here, otherwise the directory stays
+		// unwritable to everyone but root, the parent directory stays private to the core
+		if request.Security != nil && !request.Security.runsAsRoot() {
+			err = os.Chmod(socketDirectory, 0777)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		cfg.Env = []string{"RIN_LISTEN=unix://" + path.Join(ContainerSocketDirectory, "rin.sock")}
+		hostConfig.Mounts = []mount.Mount{{
+			Type:   mount.TypeBind,
+			Source: socketDirectory,
+			Target: ContainerSocketDirectory,
+		}}
+		address = "unix://" + filepath.Join(socketDirectory, "rin.sock")
+	}
+
+	// over TCP the core reaches rin through the internal network, the default network only provides egress
+	connectDefault := false
+	published := false
+	switch {
+	case r.SocketDirectory == "" && request.Network == NetworkBridge:
+		hostConfig.NetworkMode = container.NetworkMode(r.DefaultNetwork)
+		cfg.ExposedPorts, hostConfig.PortBindings = r.createPortConfig(request.PortInternal)
+		published = true
+	case r.SocketDirectory == "":
+		hostConfig.NetworkMode = InternalNetwork
+		connectDefault = request.Network == NetworkCompile
+	case request.Network == NetworkNone:
+		hostConfig.NetworkMode = "none"
+	default:
+		hostConfig.NetworkMode = container.NetworkMode(r.DefaultNetwork)
+	}
+
+	if r.LimitMemory {
+		hostConfig.Resources.Memory = r.MemoryHardLimit * 1024
+	}
+	pinned := r.LimitCpuset && request.CpusetCpus != ""
+	if r.LimitCpu && !pinned {
+		hostConfig.Resources.NanoCPUs = int64(r.CpuHardLimit * 1e9)
+	}
+	if pinned {
+		hostConfig.Resources.CpusetCpus = request.CpusetCpus
+	}
+
+	if request.Security != nil {
+		err := applySecurityProfile(request.Security, &cfg, &hostConfig)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	createResponse, err := r.Client.ContainerCreate(ctx, &cfg, &hostConfig, nil, nil, slotName)
@@ -72,20 +253,77 @@ func (r *DockerRunner) Start(ctx context.Context, request *ContainerStartRequest
 		return nil, fmt.Errorf("failed to create a container %w", err)
 	}
 
-	containerId := createResponse.ID
+	containerId = createResponse.ID
+	if connectDefault {
+		err = r.Client.NetworkConnect(ctx, r.DefaultNetwork, containerId, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect a container to %s %w", r.DefaultNetwork, err)
+		}
+	}
+
 	err = r.Client.ContainerStart(ctx, containerId, types.ContainerStartOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to start a container %w", err)
 	}
-	dockerContainer := ContainerInfo{
+
+	hostPort := 0
+	if address == "" {
+		inspect, err := r.Client.ContainerInspect(ctx, containerId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect a container %w", err)
+		}
+
+		if published {
+			hostPort, err = publishedPort(inspect, request.PortInternal)
+			if err != nil {
+				return nil, fmt.Errorf("container %s %w", containerId, err)
+			}
+			address = net.JoinHostPort(r.BindAddress, strconv.Itoa(hostPort))
+		} else {
+			endpoint := inspect.NetworkSettings.Networks[InternalNetwork]
+			if endpoint == nil || endpoint.IPAddress == "" {
+				return nil, fmt.Errorf("container %s has no address on %s", containerId, InternalNetwork)
+			}
+			address = net.JoinHostPort(endpoint.IPAddress, strconv.Itoa(request.PortInternal))
+		}
+	}
+	dockerContainer := &ContainerInfo{
 		ContainerId: containerId,
+		Port:        hostPort,
+		Address:     address,
 		Request:     *request,
 	}
+	r.watch(dockerContainer)
+
+	return dockerContainer, nil
+}
+
+// abortStart removes the container and the socket directory of a failed start, the context of the start
+// may be what failed it
+func (r *DockerRunner) abortStart(containerId string, slotName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), AbortStartTimeout)
+	defer cancel()
+
+	if containerId != "" {
+		err := r.Client.ContainerRemove(ctx, containerId, types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+			Force:         true,
+		})
+		if err != nil {
+			log.Println("failed to remove container", containerId, "of a failed start", err)
+		}
+	}
 
-	return &dockerContainer, nil
+	if r.SocketDirectory != "" {
+		err := os.RemoveAll(filepath.Join(r.SocketDirectory, slotName))
+		if err != nil {
+			log.Println("failed to remove socket directory of", slotName, err)
+		}
+	}
 }
 
 func (r *DockerRunner) Stop(ctx context.Context, info *ContainerInfo) error {
+	r.unwatch(info)
 	err := r.Client.ContainerRemove(ctx, info.ContainerId, types.ContainerRemoveOptions{
 		RemoveVolumes: true,
 		Force:         true,
@@ -94,31 +332,58 @@ func (r *DockerRunner) Stop(ctx context.Context, info *ContainerInfo) error {
 	if err != nil {
 		fmt.Printf("failed to remove container %s %v\n", info.ContainerId, err)
 	}
+
+	if r.SocketDirectory != "" {
+		return os.RemoveAll(filepath.Join(r.SocketDirectory, r.containerName(info.Request.Slot)))
+	}
+	return nil
+}
+
+func (r *DockerRunner) Isolate(ctx context.Context, info *ContainerInfo) error {
+	if info.Request.Network != NetworkCompile {
+		return nil
+	}
+
+	err := r.Client.NetworkDisconnect(ctx, r.DefaultNetwork, info.ContainerId, true)
+	if err != nil {
+		return fmt.Errorf("failed to disconnect container %s from %s %w", info.ContainerId, r.DefaultNetwork, err)
+	}
 	return nil
 }
 
+// CleanUp reaps the containers of dead instances and removes the socket directories left behind by them
 func (r *DockerRunner) CleanUp(ctx context.Context) error {
-	list, err := r.Client.ContainerList(ctx, types.ContainerListOptions{All: true})
+	err := r.Reap(ctx, 0)
 	if err != nil {
 		return err
 	}
+	return r.removeStaleSocketDirectories(ctx)
+}
 
-	for _, c := range list {
-		names := c.Names
-		log.Println(names)
-		if len(names) > 0 && strings.HasPrefix(names[0], "/"+Prefix) {
-			log.Printf("found stray runner container %s", names[0])
-
-			err := r.Client.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
-				RemoveVolumes: true,
-				Force:         true,
-			})
+// publishedPort reads the host port the engine assigned to the port of rin
+func publishedPort(inspect types.ContainerJSON, port int) (int, error) {
+	if inspect.NetworkSettings == nil {
+		return 0, fmt.Errorf("has no network settings")
+	}
 
-			if err != nil {
-				log.Printf("failed to delete stray container %v\n", c)
-			}
+	for _, binding := range inspect.NetworkSettings.Ports[nat.Port(strconv.Itoa(port)+"/tcp")] {
+		if binding.HostPort != "" {
+			return strconv.Atoi(binding.HostPort)
 		}
 	}
+	return 0, fmt.Errorf("has no host port published for %d", port)
+}
 
-	return nil
+// prepareSocketDirectory creates the directory holding the sockets of the containers, only the core may use them
+func prepareSocketDirectory(socketDirectory string) (string, error) {
+	if socketDirectory == "" {
+		return "", nil
+	}
+
+	// the directory is bind mounted by the engine which requires an absolute path
+	socketDirectory, err := filepath.Abs(socketDirectory)
+	if err != nil {
+		return "", err
+	}
+	return socketDirectory, os.MkdirAll(socketDirectory, 0700)
 }
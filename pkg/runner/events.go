@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"context"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"log"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// EventsRetryDelay is how long the watcher waits before subscribing again once the event stream breaks,
+// such as when the engine restarts
+const EventsRetryDelay = time.Second
+
+// watch registers a started container so that its death is reported on its ContainerInfo
+func (r *DockerRunner) watch(info *ContainerInfo) {
+	r.watchLock.Lock()
+	defer r.watchLock.Unlock()
+	r.watched[info.ContainerId] = info
+}
+
+func (r *DockerRunner) unwatch(info *ContainerInfo) {
+	r.watchLock.Lock()
+	defer r.watchLock.Unlock()
+	delete(r.watched, info.ContainerId)
+}
+
+func (r *DockerRunner) markStarting(name string) {
+	r.watchLock.Lock()
+	defer r.watchLock.Unlock()
+	r.starting[name] = true
+}
+
+func (r *DockerRunner) unmarkStarting(name string) {
+	r.watchLock.Lock()
+	defer r.watchLock.Unlock()
+	delete(r.starting, name)
+}
+
+// isStarting tells whether a container with one of the names is being started, Docker reports names
+// with a leading slash, Podman may not
+func (r *DockerRunner) isStarting(names []string) bool {
+	r.watchLock.Lock()
+	defer r.watchLock.Unlock()
+	for _, name := range names {
+		if r.starting[strings.TrimPrefix(name, "/")] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *DockerRunner) watchedContainer(containerId string) *ContainerInfo {
+	r.watchLock.Lock()
+	defer r.watchLock.Unlock()
+	return r.watched[containerId]
+}
+
+// watchEvents follows the die and oom events of the engine until the context is done
+func (r *DockerRunner) watchEvents(ctx context.Context) {
+	for ctx.Err() == nil {
+		subscribeContext, cancel := context.WithCancel(ctx)
+		messages, errs := r.Client.Events(subscribeContext, types.EventsOptions{
+			Filters: filters.NewArgs(
+				filters.Arg("type", events.ContainerEventType),
+				filters.Arg("event", "die"),
+				filters.Arg("event", "oom"),
+			),
+		})
+
+		// events missed while not subscribed are caught up with by inspecting the watched containers
+		r.reconcile(ctx)
+
+		err := r.followEvents(messages, errs)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Println("lost the event stream of the engine, subscribing again:", err)
+		time.Sleep(EventsRetryDelay)
+	}
+}
+
+// followEvents handles events until the stream breaks
+func (r *DockerRunner) followEvents(messages <-chan events.Message, errs <-chan error) error {
+	for {
+		select {
+		case message := <-messages:
+			info := r.watchedContainer(message.Actor.ID)
+			if info == nil {
+				continue
+			}
+
+			switch message.Action {
+			case "oom":
+				// any process of the container may have been killed, only the die event tells that rin is gone
+				info.MarkOomKilled()
+			case "die":
+				info.MarkExited(r.inspectOomKilled(message.Actor.ID))
+			}
+		case err := <-errs:
+			return err
+		}
+	}
+}
+
+// reconcile marks the watched containers which are no longer running as exited
+func (r *DockerRunner) reconcile(ctx context.Context) {
+	r.watchLock.Lock()
+	watched := make([]*ContainerInfo, 0, len(r.watched))
+	for _, info := range r.watched {
+		watched = append(watched, info)
+	}
+	r.watchLock.Unlock()
+
+	for _, info := range watched {
+		inspect, err := r.Client.ContainerInspect(ctx, info.ContainerId)
+		if client.IsErrNotFound(err) {
+			info.MarkExited(false)
+			continue
+		}
+		if err != nil || inspect.State == nil {
+			continue
+		}
+
+		if !inspect.State.Running {
+			info.MarkExited(killedByOom(inspect.State))
+		}
+	}
+}
+
+func (r *DockerRunner) inspectOomKilled(containerId string) bool {
+	inspect, err := r.Client.ContainerInspect(context.Background(), containerId)
+	if err != nil || inspect.State == nil {
+		return false
+	}
+	return killedByOom(inspect.State)
+}
+
+// killedByOom tells whether rin itself was killed by the OOM killer, the engine flags the container
+// as soon as any of its processes is, which only explains the exit if rin died of SIGKILL
+func killedByOom(state *types.ContainerState) bool {
+	return state.OOMKilled && state.ExitCode == 128+int(syscall.SIGKILL)
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"io"
+	"log"
+	"os"
+)
+
+// PrepareImage makes sure the image of the template is available to the engine, loading or pulling it if
+// allowed, and returns its content addressed id so that a re-tagged image does not change under the core
+func (r *DockerRunner) PrepareImage(ctx context.Context, template *ContainerTemplate) (string, error) {
+	inspect, _, err := r.Client.ImageInspectWithRaw(ctx, template.Image)
+	if client.IsErrNotFound(err) {
+		err = r.fetchImage(ctx, template)
+		if err != nil {
+			return "", err
+		}
+		inspect, _, err = r.Client.ImageInspectWithRaw(ctx, template.Image)
+	}
+	if err != nil {
+		return "", fmt.Errorf("image %s is not available %w", template.Image, err)
+	}
+	return inspect.ID, nil
+}
+
+// fetchImage loads the image from the tarball of the template, or pulls it if the template allows it
+func (r *DockerRunner) fetchImage(ctx context.Context, template *ContainerTemplate) error {
+	switch {
+	case template.ImageTarball != "":
+		log.Printf("loading image %s from %s", template.Image, template.ImageTarball)
+		file, err := os.Open(template.ImageTarball)
+		if err != nil {
+			return err
+		}
+		defer closeStream(file)
+
+		response, err := r.Client.ImageLoad(ctx, file, true)
+		if err != nil {
+			return fmt.Errorf("failed to load image %s %w", template.ImageTarball, err)
+		}
+		defer closeStream(response.Body)
+		return readProgress(response.Body)
+	case template.Pull:
+		log.Printf("pulling image %s", template.Image)
+		body, err := r.Client.ImagePull(ctx, template.Image, types.ImagePullOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to pull image %s %w", template.Image, err)
+		}
+		defer closeStream(body)
+		return readProgress(body)
+	default:
+		return fmt.Errorf("image %s not found, set pull or imageTarball in template %s to fetch it", template.Image, template.Id)
+	}
+}
+
+// readProgress waits for the engine to finish, a failure is reported in the stream rather than by the status code
+func readProgress(stream io.Reader) error {
+	decoder := json.NewDecoder(stream)
+	for {
+		var message struct {
+			Error string `json:"error"`
+		}
+		err := decoder.Decode(&message)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if message.Error != "" {
+			return errors.New(message.Error)
+		}
+	}
+}
+
+func closeStream(stream io.Closer) {
+	err := stream.Close()
+	if err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,417 @@
+//go:build linux
+
+package runner
+
+import (
+	"GradingCore2/pkg/platform"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/sys/unix"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	NativeRinPath     = "/bin/rin"
+	NativeSharedPath  = "/run" // replaced by a directory shared with the host, rin listens on a socket in it
+	NativeSocketName  = "rin.sock"
+	NativeJudgePath   = "/tmp/judge"  // created by rin and private to it, the root is read-only
+	NativeIdRange     = 65536         // uids and gids mapped into each sandbox
+	NativeSandboxUser = "65534:65534" // mapped user submitted programs run as, rin is the mapped root
+	NativePidsLimit   = 128
+)
+
+// NativeRunner starts rin directly on the host in new user, mount, pid, network, ipc and uts namespaces,
+// chrooted into a read-only view of the rootfs of the template with a cgroup v2 leaf enforcing the limits,
+// mounting requires the runner to be root while the sandbox only gets the unprivileged ids from UidBase
+type NativeRunner struct {
+	Directory       string           // holds the root and the shared directory of each sandbox
+	Cgroup          *platform.Cgroup // parent of the cgroup of each sandbox
+	UidBase         int              // host uid and gid the root of the sandboxes is mapped to
+	CpuHardLimit    float64
+	MemoryHardLimit int64
+	sandboxes       map[string]*nativeSandbox
+	lock            sync.Mutex
+}
+
+type nativeSandbox struct {
+	Directory string
+	Command   *exec.Cmd
+	Cgroup    *platform.Cgroup
+	Mounts    []string // mount points in the order they were mounted
+	Exited    chan struct{}
+}
+
+func NewNativeRunner(cpuHardLimit float64, memoryHardLimit int64, directory string, cgroupPath string, uidBase int) (*NativeRunner, error) {
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(directory, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(cgroupPath, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cgroup %s: %w", cgroupPath, err)
+	}
+
+	cgroup := &platform.Cgroup{Path: cgroupPath}
+	err = cgroup.EnableControllers("cpu", "cpuset", "memory", "pids")
+	if err != nil {
+		return nil, err
+	}
+
+	return &NativeRunner{
+		Directory:       directory,
+		Cgroup:          cgroup,
+		UidBase:         uidBase,
+		CpuHardLimit:    cpuHardLimit,
+		MemoryHardLimit: memoryHardLimit,
+		sandboxes:       make(map[string]*nativeSandbox),
+	}, nil
+}
+
+func (r *NativeRunner) Start(_ context.Context, request *ContainerStartRequest) (*ContainerInfo, error) {
+	if request.Rootfs == "" || request.WorkDir == "" {
+		return nil, errors.New("the template needs a rootfs and a work directory for the native runner")
+	}
+	if request.Network != NetworkNone {
+		return nil, errors.New("sandboxes of the native runner have no network, the template network must be none")
+	}
+
+	name := fmt.Sprintf("%s%d", Prefix, request.Slot)
+	sandbox := &nativeSandbox{
+		Directory: filepath.Join(r.Directory, name),
+		Exited:    make(chan struct{}),
+	}
+
+	err := r.prepare(sandbox, request)
+	if err == nil {
+		err = r.launch(sandbox, name, request)
+	}
+	if err != nil {
+		releaseErr := r.release(sandbox)
+		if releaseErr != nil {
+			log.Println("failed to release sandbox", name, releaseErr)
+		}
+		return nil, err
+	}
+
+	r.lock.Lock()
+	r.sandboxes[name] = sandbox
+	r.lock.Unlock()
+
+	// the isolation of the sandbox is fixed, a security profile does not apply to it
+	started := *request
+	started.Security = nil
+	return &ContainerInfo{
+		ContainerId: name,
+		Address:     "unix://" + filepath.Join(sandbox.Directory, "shared", NativeSocketName),
+		Request:     started,
+	}, nil
+}
+
+// prepare mounts the root of the sandbox, the rootfs is read-only and only the work directory,
+// the temporary directory and the shared directory are writable
+func (r *NativeRunner) prepare(sandbox *nativeSandbox, request *ContainerStartRequest) error {
+	root := filepath.Join(sandbox.Directory, "root")
+	shared := filepath.Join(sandbox.Directory, "shared")
+	for _, directory := range []string{root, shared} {
+		err := os.MkdirAll(directory, 0755)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := os.Chown(shared, r.UidBase, r.UidBase)
+	if err != nil {
+		return err
+	}
+
+	err = r.mount(sandbox, request.Rootfs, root, "", unix.MS_BIND|unix.MS_REC, "")
+	if err != nil {
+		return err
+	}
+
+	err = unix.Mount("", root, "", unix.MS_PRIVATE|unix.MS_REC, "")
+	if err != nil {
+		return fmt.Errorf("failed to make %s private: %w", root, err)
+	}
+
+	err = unix.Mount("", root, "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY|unix.MS_NOSUID, "")
+	if err != nil {
+		return fmt.Errorf("failed to make %s read-only: %w", root, err)
+	}
+
+	// submitted programs build and write their files as the sandbox user, the sticky bit keeps them from
+	// removing what rin put there, the work directory starts empty and rin copies files such as a go.mod
+	// into it from RIN_SEED
+	tmpfs := map[string]string{request.WorkDir: "1777", "/tmp": "1777"}
+	for path, mode := range tmpfs {
+		data := fmt.Sprintf("mode=%s,uid=%d,gid=%d", mode, r.UidBase, r.UidBase)
+		err = r.mount(sandbox, "tmpfs", filepath.Join(root, path), "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return r.mount(sandbox, shared, filepath.Join(root, NativeSharedPath), "", unix.MS_BIND, "")
+}
+
+func (r *NativeRunner) mount(sandbox *nativeSandbox, source string, target string, fsType string, flags uintptr, data string) error {
+	err := unix.Mount(source, target, fsType, flags, data)
+	if err != nil {
+		return fmt.Errorf("failed to mount %s on %s: %w", source, target, err)
+	}
+	sandbox.Mounts = append(sandbox.Mounts, target)
+	return nil
+}
+
+// launch starts rin as the init process of the sandbox inside its own cgroup
+func (r *NativeRunner) launch(sandbox *nativeSandbox, name string, request *ContainerStartRequest) error {
+	cgroup, err := r.Cgroup.Child(name)
+	if err != nil {
+		return err
+	}
+	sandbox.Cgroup = cgroup
+
+	err = cgroup.SetMemoryLimit(r.MemoryHardLimit)
+	if err != nil {
+		return err
+	}
+
+	err = cgroup.SetPidsLimit(NativePidsLimit)
+	if err != nil {
+		return err
+	}
+
+	if request.CpusetCpus != "" {
+		err = cgroup.SetCpuset(request.CpusetCpus)
+	} else {
+		err = cgroup.SetCpuLimit(r.CpuHardLimit)
+	}
+	if err != nil {
+		return err
+	}
+
+	logFile, err := os.Create(filepath.Join(sandbox.Directory, "rin.log"))
+	if err != nil {
+		return err
+	}
+	defer closeFile(logFile)
+
+	command := exec.Command(NativeRinPath)
+	command.Dir = request.WorkDir
+	command.Env = append(append([]string{}, request.Env...),
+		"RIN_LISTEN=unix://"+filepath.Join(NativeSharedPath, NativeSocketName),
+		"RIN_MOUNT_PROC=1",
+		"RIN_JUDGE_DIR="+NativeJudgePath,
+		// without capabilities the sandbox user can neither trace rin nor signal it
+		"RIN_SANDBOX_USER="+NativeSandboxUser,
+	)
+	command.Stdout = logFile
+	command.Stderr = logFile
+	command.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID |
+			syscall.CLONE_NEWNET | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: r.UidBase, Size: NativeIdRange}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: r.UidBase, Size: NativeIdRange}},
+		// switching to the mapped root also drops the supplementary groups of the runner
+		GidMappingsEnableSetgroups: true,
+		Credential:                 &syscall.Credential{Uid: 0, Gid: 0},
+		Chroot:                     filepath.Join(sandbox.Directory, "root"),
+		Pdeathsig:                  syscall.SIGKILL,
+	}
+
+	cgroupFile, err := cgroup.Attach(command)
+	if err != nil {
+		return err
+	}
+	defer closeFile(cgroupFile)
+
+	err = command.Start()
+	if err != nil {
+		return fmt.Errorf("failed to start rin in %s: %w", name, err)
+	}
+	sandbox.Command = command
+
+	go func() {
+		err := command.Wait()
+		if err != nil {
+			log.Println("rin in", name, "exited", err)
+		}
+		close(sandbox.Exited)
+	}()
+	return nil
+}
+
+func (r *NativeRunner) Stop(ctx context.Context, info *ContainerInfo) error {
+	r.lock.Lock()
+	sandbox := r.sandboxes[info.ContainerId]
+	delete(r.sandboxes, info.ContainerId)
+	r.lock.Unlock()
+
+	if sandbox == nil {
+		return fmt.Errorf("sandbox %s not found", info.ContainerId)
+	}
+
+	// rin is the init of the pid namespace, every other process of the sandbox goes with it
+	if sandbox.Command != nil {
+		err := sandbox.Command.Process.Kill()
+		if err != nil && !errors.Is(err, os.ErrProcessDone) {
+			return err
+		}
+
+		select {
+		case <-sandbox.Exited:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return r.release(sandbox)
+}
+
+// release removes the cgroup and the mounts of the sandbox, the directory is only removed once
+// nothing is mounted in it anymore so that the rootfs can never be deleted through it
+func (r *NativeRunner) release(sandbox *nativeSandbox) error {
+	if sandbox.Cgroup != nil {
+		err := sandbox.Cgroup.Destroy()
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := len(sandbox.Mounts) - 1; i >= 0; i-- {
+		err := unix.Unmount(sandbox.Mounts[i], unix.MNT_DETACH)
+		if err != nil {
+			return fmt.Errorf("failed to unmount %s: %w", sandbox.Mounts[i], err)
+		}
+	}
+	return os.RemoveAll(sandbox.Directory)
+}
+
+// Isolate does nothing since the sandboxes never have a network
+func (r *NativeRunner) Isolate(_ context.Context, _ *ContainerInfo) error {
+	return nil
+}
+
+// PrepareImage checks that the rootfs of the template holds rin, there is no image to pin
+func (r *NativeRunner) PrepareImage(_ context.Context, template *ContainerTemplate) (string, error) {
+	if template.Rootfs == "" {
+		return "", fmt.Errorf("template %s has no rootfs", template.Id)
+	}
+
+	_, err := os.Stat(filepath.Join(template.Rootfs, NativeRinPath))
+	if err != nil {
+		return "", fmt.Errorf("rootfs of template %s has no rin %w", template.Id, err)
+	}
+	return "", nil
+}
+
+// Reap has nothing to do, a sandbox does not outlive the process of the core thanks to Pdeathsig
+func (r *NativeRunner) Reap(_ context.Context, _ time.Duration) error {
+	return nil
+}
+
+// PinsCpus is always true, the cpuset controller is enabled on the parent cgroup
+func (r *NativeRunner) PinsCpus() bool {
+	return true
+}
+
+// CleanUp releases the sandboxes left over by a previous run
+func (r *NativeRunner) CleanUp(_ context.Context) error {
+	entries, err := os.ReadDir(r.Cgroup.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), Prefix) {
+			log.Printf("found stray runner cgroup %s", entry.Name())
+			err := (&platform.Cgroup{Path: filepath.Join(r.Cgroup.Path, entry.Name())}).Destroy()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	mounts, err := mountsUnder(r.Directory)
+	if err != nil {
+		return err
+	}
+
+	entries, err = os.ReadDir(r.Directory)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), Prefix) {
+			continue
+		}
+
+		log.Printf("found stray runner sandbox %s", entry.Name())
+		sandbox := &nativeSandbox{Directory: filepath.Join(r.Directory, entry.Name())}
+		for _, mount := range mounts {
+			if strings.HasPrefix(mount, sandbox.Directory+"/") {
+				sandbox.Mounts = append(sandbox.Mounts, mount)
+			}
+		}
+
+		err := r.release(sandbox)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mountsUnder lists the mount points below the directory, parents come before their children
+func mountsUnder(directory string) ([]string, error) {
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile(file)
+
+	mounts := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+
+		// spaces and other special characters of the mount point are escaped as octal
+		mountPoint, err := strconv.Unquote(`"` + fields[4] + `"`)
+		if err != nil {
+			mountPoint = fields[4]
+		}
+		if strings.HasPrefix(mountPoint, directory+"/") {
+			mounts = append(mounts, mountPoint)
+		}
+	}
+
+	sort.Strings(mounts)
+	return mounts, scanner.Err()
+}
+
+func closeFile(file *os.File) {
+	err := file.Close()
+	if err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,46 @@
+//go:build !linux
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var errNativeUnsupported = errors.New("the native runner is only supported on linux")
+
+type NativeRunner struct {
+}
+
+func NewNativeRunner(_ float64, _ int64, _ string, _ string, _ int) (*NativeRunner, error) {
+	return nil, errNativeUnsupported
+}
+
+func (r *NativeRunner) Start(_ context.Context, _ *ContainerStartRequest) (*ContainerInfo, error) {
+	return nil, errNativeUnsupported
+}
+
+func (r *NativeRunner) Stop(_ context.Context, _ *ContainerInfo) error {
+	return errNativeUnsupported
+}
+
+func (r *NativeRunner) Isolate(_ context.Context, _ *ContainerInfo) error {
+	return errNativeUnsupported
+}
+
+func (r *NativeRunner) CleanUp(_ context.Context) error {
+	return errNativeUnsupported
+}
+
+func (r *NativeRunner) PrepareImage(_ context.Context, _ *ContainerTemplate) (string, error) {
+	return "", errNativeUnsupported
+}
+
+func (r *NativeRunner) Reap(_ context.Context, _ time.Duration) error {
+	return errNativeUnsupported
+}
+
+func (r *NativeRunner) PinsCpus() bool {
+	return false
+}
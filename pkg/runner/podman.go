@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"github.com/docker/docker/client"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// DefaultPodmanSocket is where a rootless Podman service listens for the current user
+func DefaultPodmanSocket() string {
+	return filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "podman", "podman.sock")
+}
+
+// NewPodmanRunner connects to a Podman service through its Docker compatible API, a rootless Podman
+// can only enforce the resource limits whose cgroup controllers are delegated to the user, the other
+// ones are left to rin and reported at start
+func NewPodmanRunner(cpuHardLimit float64, memoryHardLimit int64, socketPath string, socketDirectory string) (*DockerRunner, error) {
+	podmanClient, err := client.NewClientWithOpts(client.WithHost("unix://"+socketPath), client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := podmanClient.Info(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query podman at %s: %w", socketPath, err)
+	}
+
+	limitCpu := info.CPUCfsQuota && info.CPUCfsPeriod
+	if !info.MemoryLimit {
+		log.Println("podman cannot limit container memory, is the memory controller delegated? cgroup", info.CgroupVersion)
+	}
+	if !limitCpu {
+		log.Println("podman cannot limit container CPU, is the cpu controller delegated? cgroup", info.CgroupVersion)
+	}
+	if !info.CPUSet {
+		log.Println("podman cannot pin containers to CPUs, is the cpuset controller delegated? cgroup", info.CgroupVersion)
+	}
+
+	return newDockerRunner(podmanClient, cpuHardLimit, memoryHardLimit, socketDirectory, "podman",
+		info.MemoryLimit, limitCpu, info.CPUSet)
+}
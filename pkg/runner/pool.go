@@ -0,0 +1,192 @@
+package runner
+
+import (
+	"GradingCore2/pkg/protorin"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// PoolStartTimeout bounds how long a container started for the warm pool may take to answer Ping
+const PoolStartTimeout = 30 * time.Second
+
+// containerPool keeps started and pinged containers of a template ready to be handed out
+type containerPool struct {
+	Template *ContainerTemplate
+	Ready    []*ContainerInfo
+	Starting int
+}
+
+// ResetTimeout bounds the Reset call made before a container goes back to its pool
+const ResetTimeout = 5 * time.Second
+
+// RegisterTemplate enables the warm pool of the template, Tick keeps at least PoolMin of its containers ready
+// and released containers which can still be reused go back to it while it holds fewer than PoolMax
+func (s *Service) RegisterTemplate(template *ContainerTemplate) {
+	if template.PoolMax < template.PoolMin {
+		template.PoolMax = template.PoolMin
+	}
+	if template.PoolMax <= 0 {
+		return
+	}
+
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	s.Pools[template.Id] = &containerPool{Template: template}
+}
+
+// takeReady pops a ready container of the template from its pool, it must be called with the lock held
+func (s *Service) takeReady(template *ContainerTemplate) *ContainerInfo {
+	pool := s.Pools[template.Id]
+	if pool == nil || len(pool.Ready) == 0 {
+		return nil
+	}
+
+	for len(pool.Ready) > 0 {
+		info := pool.Ready[0]
+		pool.Ready = pool.Ready[1:]
+		if !info.Exited() {
+			return info
+		}
+		info.WaitForShutdown = true
+	}
+	return nil
+}
+
+// takeIdle pops a ready container from the pool holding the most of them so that its slot can be used
+// by another template, it must be called with the lock held
+func (s *Service) takeIdle() *ContainerInfo {
+	var largest *containerPool
+	for _, pool := range s.Pools {
+		s.dropExited(pool)
+		if len(pool.Ready) > 0 && (largest == nil || len(pool.Ready) > len(largest.Ready)) {
+			largest = pool
+		}
+	}
+	if largest == nil {
+		return nil
+	}
+
+	info := largest.Ready[len(largest.Ready)-1]
+	largest.Ready = largest.Ready[:len(largest.Ready)-1]
+	return info
+}
+
+// countIdle counts the containers waiting in a pool, it must be called with the lock held
+func (s *Service) countIdle() int {
+	count := 0
+	for _, pool := range s.Pools {
+		count += len(pool.Ready)
+	}
+	return count
+}
+
+// replenish starts the containers missing from each pool in the background and destroys
+// the ones above PoolMax
+func (s *Service) replenish() {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	for _, pool := range s.Pools {
+		s.dropExited(pool)
+		for len(pool.Ready)+pool.Starting < pool.Template.PoolMin {
+			pool.Starting++
+			go s.warmUp(pool)
+		}
+
+		for len(pool.Ready) > pool.Template.PoolMax {
+			info := pool.Ready[len(pool.Ready)-1]
+			pool.Ready = pool.Ready[:len(pool.Ready)-1]
+			info.WaitForShutdown = true
+		}
+	}
+}
+
+// dropExited removes the containers which died while waiting in the pool, they are shut down by the next Tick
+func (s *Service) dropExited(pool *containerPool) {
+	ready := pool.Ready[:0]
+	for _, info := range pool.Ready {
+		if info.Exited() {
+			log.Println("container", info.ContainerId, "of pool", pool.Template.Id, "exited")
+			info.WaitForShutdown = true
+			continue
+		}
+		ready = append(ready, info)
+	}
+	pool.Ready = ready
+}
+
+// warmUp starts a container for the pool and adds it once it answers Ping
+func (s *Service) warmUp(pool *containerPool) {
+	ctx, cancel := context.WithTimeout(context.Background(), PoolStartTimeout)
+	defer cancel()
+
+	s.Lock.Lock()
+	// the pools must not evict each other
+	info, err := s.create(ctx, pool.Template, slotNoWait)
+	ready := false
+	if err == nil {
+		ready, err = info.Wait(PoolStartTimeout)
+		if !ready {
+			destroyErr := s.Destroy(context.Background(), info)
+			if destroyErr != nil {
+				log.Println("failed to destroy container", info.ContainerId, destroyErr)
+			}
+		}
+	}
+
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	pool.Starting--
+	if !ready {
+		log.Println("failed to warm up a container for", pool.Template.Id, err)
+		return
+	}
+	pool.Ready = append(pool.Ready, info)
+}
+
+// Release hands a container back once its submission is done, the next Tick resets it and puts it back in
+// the pool if it may serve another submission, otherwise it is shut down
+func (s *Service) Release(info *ContainerInfo) {
+	info.Lock.Lock()
+	defer info.Lock.Unlock()
+
+	info.Uses++
+	// a container cut from the network it needs for compilation cannot serve another submission
+	reusable := info.Template != nil && info.Uses < info.Template.MaxUses && info.GrpcClient != nil && !info.Exited()
+	if reusable && !(info.Isolated && info.Request.Network == NetworkCompile) {
+		info.WaitForReset = true
+	} else {
+		info.WaitForShutdown = true
+	}
+}
+
+// recycle resets a released container and puts it back in its pool, it is shut down if the reset fails
+// or the pool is full, it must be called with the lock of the container held
+func (s *Service) recycle(info *ContainerInfo) {
+	info.WaitForReset = false
+	info.ResetOomKills()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ResetTimeout)
+	defer cancel()
+
+	result, err := info.GrpcClient.Reset(ctx, &protorin.Empty{})
+	if err == nil && !result.GetClean() {
+		err = fmt.Errorf("sandbox is not clean: %s", result.GetMessage())
+	}
+	if err != nil {
+		log.Println("failed to reset container", info.ContainerId, err)
+		info.WaitForShutdown = true
+		return
+	}
+
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	pool := s.Pools[info.Template.Id]
+	if pool == nil || len(pool.Ready) >= info.Template.PoolMax {
+		info.WaitForShutdown = true
+		return
+	}
+	pool.Ready = append(pool.Ready, info)
+}
@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"GradingCore2/pkg/platform"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// labels identifying the core instance which owns a container, several instances may share one engine
+const (
+	LabelInstance = "gradingcore.instance"
+	LabelCreated  = "gradingcore.created" // unix time in seconds
+	LabelHost     = "gradingcore.host"
+	LabelPid      = "gradingcore.pid"
+)
+
+// newInstanceId identifies the containers of this core among the ones of other cores sharing the engine
+func newInstanceId() (string, error) {
+	id := make([]byte, 6)
+	_, err := rand.Read(id)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// containerName is unique to the slot and the instance, the name of a slot of another instance never collides with it
+func (r *DockerRunner) containerName(slot int) string {
+	return Prefix + r.Instance + "-" + strconv.Itoa(slot)
+}
+
+// labels marks a container as owned by this instance
+func (r *DockerRunner) labels() map[string]string {
+	return map[string]string{
+		LabelInstance: r.Instance,
+		LabelCreated:  strconv.FormatInt(time.Now().Unix(), 10),
+		LabelHost:     r.Hostname,
+		LabelPid:      strconv.Itoa(os.Getpid()),
+	}
+}
+
+// Reap removes the containers left behind by dead instances of this host, and the ones of this instance which
+// are not tracked anymore once older than maxAge, the age rule is skipped if maxAge is 0
+func (r *DockerRunner) Reap(ctx context.Context, maxAge time.Duration) error {
+	list, err := r.Client.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", LabelInstance)),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range list {
+		reason := r.orphaned(&c, maxAge)
+		if reason == "" {
+			continue
+		}
+
+		log.Printf("reaping container %s of instance %s: %s", c.ID, c.Labels[LabelInstance], reason)
+		err := r.Client.ContainerRemove(ctx, c.ID, types.ContainerRemoveOptions{
+			RemoveVolumes: true,
+			Force:         true,
+		})
+		if err != nil {
+			log.Printf("failed to reap container %s %v\n", c.ID, err)
+		}
+	}
+	return nil
+}
+
+// orphaned tells why a container has to be reaped, it is empty if the container may still be in use
+func (r *DockerRunner) orphaned(c *types.Container, maxAge time.Duration) string {
+	created, err := strconv.ParseInt(c.Labels[LabelCreated], 10, 64)
+	if err != nil {
+		created = c.Created
+	}
+	expired := maxAge > 0 && time.Since(time.Unix(created, 0)) > maxAge
+
+	instance := c.Labels[LabelInstance]
+	if instance == r.Instance {
+		// such as a container whose start failed half way
+		if expired && r.watchedContainer(c.ID) == nil && !r.isStarting(c.Names) {
+			return "no longer tracked"
+		}
+		return ""
+	}
+
+	// the liveness of an instance can only be checked on its own host, the instances running there reap
+	// its containers once it is dead, an old container elsewhere may still be pooled or grading
+	if c.Labels[LabelHost] != r.Hostname {
+		return ""
+	}
+
+	pid, err := strconv.Atoi(c.Labels[LabelPid])
+	if err != nil || pid == os.Getpid() || !platform.ProcessAlive(pid) {
+		return "instance is dead"
+	}
+	return ""
+}
+
+// removeStaleSocketDirectories removes the socket directories whose container does not exist anymore
+func (r *DockerRunner) removeStaleSocketDirectories(ctx context.Context) error {
+	if r.SocketDirectory == "" {
+		return nil
+	}
+
+	list, err := r.Client.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, c := range list {
+		for _, name := range c.Names {
+			// Docker reports names with a leading slash, Podman may not
+			names[strings.TrimPrefix(name, "/")] = true
+		}
+	}
+
+	entries, err := os.ReadDir(r.SocketDirectory)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), Prefix) && !names[entry.Name()] {
+			err := os.RemoveAll(filepath.Join(r.SocketDirectory, entry.Name()))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package runner
+
+import (
+	"GradingCore2/pkg/protorin"
+	"errors"
+	"fmt"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/go-units"
+	"os"
+	"strings"
+)
+
+// SecurityProfile hardens the containers of a template started by the Docker and Podman runners,
+// the native runner has a fixed isolation of its own
+type SecurityProfile struct {
+	ReadonlyRootfs   bool              `json:"readonlyRootfs"`
+	Tmpfs            map[string]string `json:"tmpfs"` // writable mounts and their options, such as the work directory
+	DropCapabilities bool              `json:"dropCapabilities"`
+	NoNewPrivileges  bool              `json:"noNewPrivileges"`
+	PidsLimit        int64             `json:"pidsLimit"`      // processes and threads, no limit if 0
+	User             string            `json:"user"`           // user[:group] rin runs as, the user of the image if empty
+	SandboxUser      string            `json:"sandboxUser"`    // uid:gid submitted programs run as, rin itself if empty
+	FileLimit        int64             `json:"fileLimit"`      // open files, the engine default if 0
+	FileSizeLimit    int64             `json:"fileSizeLimit"`  // largest file that can be written in KiB, no limit if 0
+	SeccompProfile   string            `json:"seccompProfile"` // path to a seccomp profile, the engine default if empty
+}
+
+// DefaultSecurityProfile is used by templates which do not have a profile of their own
+func DefaultSecurityProfile() *SecurityProfile {
+	return &SecurityProfile{
+		ReadonlyRootfs: true,
+		Tmpfs: map[string]string{
+			"/src": "rw,exec,nosuid,nodev,size=64m,mode=1777",
+			"/tmp": "rw,exec,nosuid,nodev,size=256m,mode=1777",
+			// only rin can enter it, the checker and the interactor are built and run there
+			"/judge": "rw,exec,nosuid,nodev,size=64m,mode=0700",
+		},
+		DropCapabilities: true,
+		NoNewPrivileges:  true,
+		PidsLimit:        128,
+		User:             "0:0",
+		SandboxUser:      "65534:65534",
+		FileLimit:        1024,
+		FileSizeLimit:    64 << 10,
+	}
+}
+
+// rinCapabilities are kept when capabilities are dropped, rin switches to the sandbox user, limits and kills
+// what runs as it and wipes the files it left behind, programs lose them when switching
+var rinCapabilities = map[string]uint{
+	"CHOWN":        0,
+	"DAC_OVERRIDE": 1,
+	"FOWNER":       3,
+	"KILL":         5,
+	"SETGID":       6,
+	"SETUID":       7,
+	"SYS_RESOURCE": 24,
+}
+
+// applySecurityProfile translates the profile into the configuration of a Docker or Podman container
+func applySecurityProfile(profile *SecurityProfile, cfg *container.Config, hostConfig *container.HostConfig) error {
+	hostConfig.ReadonlyRootfs = profile.ReadonlyRootfs
+	if len(profile.Tmpfs) > 0 {
+		hostConfig.Tmpfs = make(map[string]string, len(profile.Tmpfs))
+		for target, options := range profile.Tmpfs {
+			hostConfig.Tmpfs[target] = options
+		}
+	}
+
+	if profile.DropCapabilities {
+		hostConfig.CapDrop = []string{"ALL"}
+		if profile.SandboxUser != "" {
+			for capability := range rinCapabilities {
+				hostConfig.CapAdd = append(hostConfig.CapAdd, capability)
+			}
+		}
+	}
+
+	if profile.NoNewPrivileges {
+		hostConfig.SecurityOpt = append(hostConfig.SecurityOpt, "no-new-privileges")
+	}
+
+	if profile.PidsLimit > 0 {
+		pidsLimit := profile.PidsLimit
+		hostConfig.Resources.PidsLimit = &pidsLimit
+	}
+
+	cfg.User = profile.User
+	if profile.SandboxUser != "" {
+		cfg.Env = append(cfg.Env, "RIN_SANDBOX_USER="+profile.SandboxUser)
+	}
+
+	if profile.FileLimit > 0 {
+		hostConfig.Resources.Ulimits = append(hostConfig.Resources.Ulimits,
+			&units.Ulimit{Name: "nofile", Soft: profile.FileLimit, Hard: profile.FileLimit})
+	}
+
+	if profile.FileSizeLimit > 0 {
+		limit := profile.FileSizeLimit * 1024
+		hostConfig.Resources.Ulimits = append(hostConfig.Resources.Ulimits,
+			&units.Ulimit{Name: "fsize", Soft: limit, Hard: limit})
+	}
+
+	// the engine expects the content of the profile rather than its path
+	if profile.SeccompProfile != "" {
+		seccomp, err := os.ReadFile(profile.SeccompProfile)
+		if err != nil {
+			return fmt.Errorf("failed to read seccomp profile %s %w", profile.SeccompProfile, err)
+		}
+		hostConfig.SecurityOpt = append(hostConfig.SecurityOpt, "seccomp="+string(seccomp))
+	}
+	return nil
+}
+
+// runsAsRoot reports whether the user of the profile is root, either by name or by id
+func (p *SecurityProfile) runsAsRoot() bool {
+	user, _, _ := strings.Cut(p.User, ":")
+	return user == "" || user == "root" || user == "0"
+}
+
+// VerifySecurity checks the self-report of rin against the profile the container was started with
+func VerifySecurity(profile *SecurityProfile, report *protorin.SecurityReport) error {
+	problems := make([]string, 0)
+
+	if profile.ReadonlyRootfs && !report.GetReadOnlyRoot() {
+		problems = append(problems, "the root filesystem is writable")
+	}
+
+	if profile.DropCapabilities {
+		var allowed uint64
+		if profile.SandboxUser != "" {
+			for _, bit := range rinCapabilities {
+				allowed |= 1 << bit
+			}
+		}
+		if extra := report.GetCapabilitiesBounding() &^ allowed; extra != 0 {
+			problems = append(problems, fmt.Sprintf("capabilities %x are not dropped", extra))
+		}
+	}
+
+	if profile.NoNewPrivileges && !report.GetNoNewPrivileges() {
+		problems = append(problems, "no-new-privileges is not set")
+	}
+
+	if profile.PidsLimit > 0 && (report.GetPidsLimit() < 0 || report.GetPidsLimit() > profile.PidsLimit) {
+		problems = append(problems, fmt.Sprintf("pids limit is %d", report.GetPidsLimit()))
+	}
+
+	if !profile.runsAsRoot() && report.GetUid() == 0 {
+		problems = append(problems, "running as root")
+	}
+
+	if profile.SandboxUser != "" && report.GetSandboxUid() <= 0 {
+		problems = append(problems, fmt.Sprintf("submitted programs run as uid %d", report.GetSandboxUid()))
+	}
+
+	if profile.FileLimit > 0 && report.GetFileLimit() > uint64(profile.FileLimit) {
+		problems = append(problems, fmt.Sprintf("open file limit is %d", report.GetFileLimit()))
+	}
+
+	if profile.FileSizeLimit > 0 && report.GetFileSizeLimit() > uint64(profile.FileSizeLimit)*1024 {
+		problems = append(problems, fmt.Sprintf("file size limit is %d", report.GetFileSizeLimit()))
+	}
+
+	if profile.SeccompProfile != "" && report.GetSeccompMode() != 2 {
+		problems = append(problems, fmt.Sprintf("seccomp mode is %d", report.GetSeccompMode()))
+	}
+
+	if len(problems) > 0 {
+		return errors.New("security profile is not in effect: " + strings.Join(problems, ", "))
+	}
+	return nil
+}
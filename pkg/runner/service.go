@@ -8,71 +8,167 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"log"
+	"strconv"
 	"sync"
 	"time"
 )
 
+var ErrContainerLimit = errors.New("container limit reached")
+
+// ReapPeriod is how often Tick looks for containers left behind
+const ReapPeriod = time.Minute
+
+// slotPolicy tells create what to do when every slot is taken
+type slotPolicy int
+
+const (
+	slotNoWait slotPolicy = iota // fail right away
+	slotEvict                    // shut down an idle container of a pool to take its slot
+	slotWait                     // evict, or wait until a released container has been destroyed
+)
+
 type Service struct {
-	RunningList  []*ContainerInfo
-	Runner       *DockerRunner
-	PortExternal int
-	Running      bool
-	Lock         sync.Mutex
+	RunningList    []*ContainerInfo // indexed by slot, holds MaxContainers slots
+	Runner         Runner
+	MaxContainers  int   // containers which may exist at once, started, starting or idle in a pool
+	ContainerLimit int   // containers allowed by TryCreate, 0 means no extra containers
+	Cpus           []int // CPUs dedicated one per container for consistent timing, no pinning if empty
+	Pools          map[string]*containerPool
+	ReapAge        time.Duration // untracked containers older than this are reaped, such as the system hard time limit
+	Running        bool
+	Lock           sync.Mutex
+
+	lastReap  time.Time
+	reaping   bool
+	slots     *slotAllocator
+	cpuOwners map[int]int // slot each pinned CPU is dedicated to
 }
 
-func NewService(cpuHardLimit float64, memoryHardLimit int64) (*Service, error) {
-	runner, err := NewDockerRunner(cpuHardLimit, memoryHardLimit)
-	if err != nil {
-		return nil, err
+func NewService(runner Runner, maxContainers int, containerLimit int, cpus []int, reapAge time.Duration) (*Service, error) {
+	if maxContainers <= 0 {
+		return nil, fmt.Errorf("invalid maximum number of containers: %d", maxContainers)
 	}
 
 	return &Service{
-		RunningList:  make([]*ContainerInfo, 1, 16),
-		Runner:       runner,
-		PortExternal: 8888,
-		Running:      true,
-		Lock:         sync.Mutex{},
+		RunningList:    make([]*ContainerInfo, maxContainers),
+		Runner:         runner,
+		MaxContainers:  maxContainers,
+		ContainerLimit: containerLimit,
+		Cpus:           cpus,
+		ReapAge:        reapAge,
+		Pools:          make(map[string]*containerPool),
+		Running:        true,
+		Lock:           sync.Mutex{},
+		slots:          newSlotAllocator(maxContainers),
+		cpuOwners:      make(map[int]int),
 	}, nil
 }
 
-func (s *Service) findEmptySlot() int {
-	for i := 0; i < len(s.RunningList); i++ {
-		if s.RunningList[i] == nil {
-			return i
-		}
+// Create hands out a ready container from the pool of the template, or starts a new one if the pool is empty,
+// taking the slot of an idle container of another pool or waiting for one if every slot is in use
+func (s *Service) Create(ctx context.Context, template *ContainerTemplate) (*ContainerInfo, error) {
+	s.Lock.Lock()
+	if info := s.takeReady(template); info != nil {
+		s.Lock.Unlock()
+		return info, nil
 	}
-
-	s.RunningList = append(s.RunningList, nil)
-	return len(s.RunningList) - 1
+	return s.create(ctx, template, slotWait)
 }
 
-func (s *Service) Create(ctx context.Context, template *ContainerTemplate) (*ContainerInfo, error) {
-	if s.PortExternal == 0 {
-		return nil, errors.New("invalid configuration, tried to set external port to 0")
+// TryCreate is like Create but only if fewer than ContainerLimit containers are in use,
+// it is meant for optional containers such as the extra shards of a submission
+func (s *Service) TryCreate(ctx context.Context, template *ContainerTemplate) (*ContainerInfo, error) {
+	s.Lock.Lock()
+	if s.countRunning()-s.countIdle() >= s.ContainerLimit {
+		s.Lock.Unlock()
+		return nil, ErrContainerLimit
+	}
+	if info := s.takeReady(template); info != nil {
+		s.Lock.Unlock()
+		return info, nil
 	}
+	return s.create(ctx, template, slotEvict)
+}
 
-	s.Lock.Lock()
+// create starts a container in a free slot, it must be called with the lock held and releases it,
+// the lock is not held while the container starts
+func (s *Service) create(ctx context.Context, template *ContainerTemplate, policy slotPolicy) (*ContainerInfo, error) {
 	startTime := time.Now()
-	slot := s.findEmptySlot()
+
+	network := template.Network
+	switch network {
+	case "":
+		network = NetworkNone
+	case NetworkNone, NetworkCompile, NetworkBridge:
+	default:
+		s.Lock.Unlock()
+		return nil, fmt.Errorf("unknown network %s for template %s", network, template.Id)
+	}
+
+	security := template.Security
+	if security == nil {
+		security = DefaultSecurityProfile()
+	}
+
+	slot, err := s.acquireSlot(ctx, policy)
+	if err != nil {
+		s.Lock.Unlock()
+		return nil, err
+	}
 	log.Println("allocated", slot)
 
+	// a dedicated CPU keeps timing consistent, it replaces the bandwidth limit of the runner as throttling
+	// a fraction of a CPU makes timing jittery and sharing one lets submissions slow each other down
+	cpu := -1
+	cpusetCpus := ""
+	if len(s.Cpus) > 0 && s.Runner.PinsCpus() {
+		cpu, err = s.takeCpu(slot)
+		if err != nil {
+			s.Lock.Unlock()
+			s.slots.release(slot)
+			return nil, err
+		}
+		cpusetCpus = strconv.Itoa(cpu)
+	}
+	s.Lock.Unlock()
+
+	image := template.Image
+	if template.PinnedImage != "" {
+		image = template.PinnedImage
+	}
+
 	info, err := s.Runner.Start(ctx, &ContainerStartRequest{
-		Image:        template.Image,
+		Image:        image,
 		Slot:         slot,
 		PortInternal: template.PortInternal,
-		PortExternal: s.PortExternal + slot,
+		CpusetCpus:   cpusetCpus,
+		Rootfs:       template.Rootfs,
+		WorkDir:      template.WorkDir,
+		Env:          template.Env,
+		Network:      network,
+		Security:     security,
 	})
 
+	s.Lock.Lock()
 	if err != nil {
+		s.releaseCpu(cpu)
 		s.Lock.Unlock()
+		s.slots.release(slot)
 		return nil, err
 	}
+	info.Cpu = cpu
+	info.Template = template
 	s.RunningList[slot] = info
 	s.Lock.Unlock()
 
-	connectionString := fmt.Sprintf("127.0.0.1:%d", info.Request.PortExternal)
+	connectionString := info.Address
 	conn, err := grpc.Dial(connectionString, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
+		// the container already holds its slot and CPU, destroying it frees them even if ctx is done
+		destroyErr := s.Destroy(context.Background(), info)
+		if destroyErr != nil {
+			log.Println("failed to destroy container", info.ContainerId, destroyErr)
+		}
 		return nil, fmt.Errorf("gRPC dial to %s (%s) failed %w", connectionString, info.ContainerId, err)
 	}
 	info.GrpcConnection = conn
@@ -82,7 +178,61 @@ func (s *Service) Create(ctx context.Context, template *ContainerTemplate) (*Con
 	return info, nil
 }
 
+// acquireSlot reserves a slot according to the policy, it must be called with the lock held which it
+// releases while waiting for a slot
+func (s *Service) acquireSlot(ctx context.Context, policy slotPolicy) (int, error) {
+	slot, err := s.slots.acquire()
+	if err == nil || policy == slotNoWait {
+		return slot, err
+	}
+
+	idle := s.takeIdle()
+	if idle == nil && policy != slotWait {
+		return slot, err
+	}
+
+	s.Lock.Unlock()
+	if idle != nil {
+		log.Println("evicting idle container", idle.ContainerId, "of pool", idle.Template.Id)
+		// the slot of the evicted container is handed over directly, replenishing a pool could take it otherwise
+		freed, destroyErr := s.destroy(ctx, idle)
+		if destroyErr == nil && freed {
+			s.Lock.Lock()
+			return idle.Request.Slot, nil
+		}
+		if destroyErr != nil {
+			log.Println("failed to destroy container", idle.ContainerId, destroyErr)
+			// it is out of its pool, the next Tick retries and frees its slot
+			idle.Lock.Lock()
+			idle.WaitForShutdown = true
+			idle.Lock.Unlock()
+		}
+	}
+
+	// a container released by its submission keeps its slot until the next Tick destroys it
+	if policy == slotWait {
+		slot, err = s.slots.wait(ctx)
+	} else {
+		slot, err = s.slots.acquire()
+	}
+	s.Lock.Lock()
+	return slot, err
+}
+
 func (s *Service) Destroy(ctx context.Context, info *ContainerInfo) error {
+	freed, err := s.destroy(ctx, info)
+	if freed {
+		s.slots.release(info.Request.Slot)
+	}
+	return err
+}
+
+// destroy stops the container and frees its CPU, the slot is left reserved for the caller to release or reuse
+// and freed tells whether the container still held it
+func (s *Service) destroy(ctx context.Context, info *ContainerInfo) (bool, error) {
+	if info == nil {
+		return false, errors.New("tried to stop a non-existing container")
+	}
 	slot := info.Request.Slot
 
 	if info.GrpcClient != nil {
@@ -99,29 +249,116 @@ func (s *Service) Destroy(ctx context.Context, info *ContainerInfo) error {
 		}
 	}
 
-	if info == nil {
-		return fmt.Errorf("tried to stop non-existing container slot %d", slot)
-	}
-
 	err := s.Runner.Stop(ctx, info)
 	if err != nil {
-		return fmt.Errorf("failed to stop a container %w", err)
+		return false, fmt.Errorf("failed to stop a container %w", err)
 	}
 
 	log.Printf("container %s (%d) shutdown ", info.ContainerId, info.Request.Slot)
-	s.RunningList[slot] = nil
+	s.Lock.Lock()
+	// a container destroyed twice must not free its slot twice
+	owned := s.RunningList[slot] == info
+	if owned {
+		s.releaseCpu(info.Cpu)
+		s.RunningList[slot] = nil
+	}
+	s.Lock.Unlock()
+	return owned, nil
+}
+
+// Isolate cuts the container from the network it had for compilation, it must be done before running any test
+func (s *Service) Isolate(ctx context.Context, info *ContainerInfo) error {
+	if info.Isolated {
+		return nil
+	}
+
+	err := s.Runner.Isolate(ctx, info)
+	if err != nil {
+		return err
+	}
+	info.Isolated = true
+	return nil
+}
+
+// PrepareTemplate makes sure the image of the template is available and pins it, it must be done before
+// the template is used
+func (s *Service) PrepareTemplate(ctx context.Context, template *ContainerTemplate) error {
+	pinned, err := s.Runner.PrepareImage(ctx, template)
+	if err != nil {
+		return err
+	}
+	template.PinnedImage = pinned
+	return nil
+}
+
+// Verify asks rin for a report of its sandbox and checks it against the security profile the container was started
+// with, it must be done before running any submitted code
+func (s *Service) Verify(ctx context.Context, info *ContainerInfo) error {
+	if info.Verified || info.Request.Security == nil {
+		return nil
+	}
+
+	report, err := info.GrpcClient.Report(ctx, &protorin.Empty{})
+	if err != nil {
+		return fmt.Errorf("failed to get the security report of container %s %w", info.ContainerId, err)
+	}
+
+	err = VerifySecurity(info.Request.Security, report)
+	if err != nil {
+		return fmt.Errorf("container %s: %w", info.ContainerId, err)
+	}
+	info.Verified = true
 	return nil
 }
 
 func (s *Service) CountRunning() int {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	return s.countRunning()
+}
+
+// countRunning counts the containers holding a slot, including the ones still starting
+func (s *Service) countRunning() int {
+	return s.slots.inUse()
+}
 
-	count := 0
+// runningContainers copies the started containers so that they can be walked without holding the lock
+func (s *Service) runningContainers() []*ContainerInfo {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	containers := make([]*ContainerInfo, 0, len(s.RunningList))
 	for _, info := range s.RunningList {
 		if info != nil {
-			count++
+			containers = append(containers, info)
 		}
 	}
-	return count
+	return containers
+}
+
+// reap removes the containers left behind in the background every ReapPeriod
+func (s *Service) reap() {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+	if s.reaping || time.Since(s.lastReap) < ReapPeriod {
+		return
+	}
+	s.reaping = true
+	s.lastReap = time.Now()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), ReapPeriod)
+		defer cancel()
+
+		err := s.Runner.Reap(ctx, s.ReapAge)
+		if err != nil {
+			log.Println("failed to reap containers", err)
+		}
+
+		s.Lock.Lock()
+		defer s.Lock.Unlock()
+		s.reaping = false
+	}()
 }
 
 func (s *Service) CleanUp(ctx context.Context) error {
@@ -129,11 +366,7 @@ func (s *Service) CleanUp(ctx context.Context) error {
 }
 
 func (s *Service) Shutdown(ctx context.Context) error {
-	for i, info := range s.RunningList {
-		if s.RunningList[i] == nil {
-			continue
-		}
-
+	for _, info := range s.runningContainers() {
 		err := s.Destroy(ctx, info)
 		if err != nil {
 			fmt.Printf("error while stopping container %s %v\n", info.ContainerId, err)
@@ -144,16 +377,21 @@ func (s *Service) Shutdown(ctx context.Context) error {
 }
 
 func (s *Service) Tick() {
-	for _, info := range s.RunningList {
-		if info == nil {
-			continue
-		}
+	if s.Running {
+		s.replenish()
+		s.reap()
+	}
 
+	for _, info := range s.runningContainers() {
 		lockSuccess := info.Lock.TryLock()
 		if !lockSuccess {
 			continue
 		}
 
+		if info.WaitForReset {
+			s.recycle(info)
+		}
+
 		if info.WaitForShutdown {
 			err := s.Destroy(context.Background(), info)
 
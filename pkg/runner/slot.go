@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrNoFreeSlot = errors.New("every container slot is in use")
+
+// slotAllocator hands out a bounded set of slots, a slot is reserved before its container is started
+// so that the bound holds while containers are still starting
+type slotAllocator struct {
+	free chan int
+}
+
+func newSlotAllocator(size int) *slotAllocator {
+	free := make(chan int, size)
+	for slot := 0; slot < size; slot++ {
+		free <- slot
+	}
+	return &slotAllocator{free: free}
+}
+
+// acquire reserves a free slot without waiting for one
+func (a *slotAllocator) acquire() (int, error) {
+	select {
+	case slot := <-a.free:
+		return slot, nil
+	default:
+		return -1, ErrNoFreeSlot
+	}
+}
+
+// wait reserves a slot, waiting for one to be released until the context is done
+func (a *slotAllocator) wait(ctx context.Context) (int, error) {
+	select {
+	case slot := <-a.free:
+		return slot, nil
+	case <-ctx.Done():
+		return -1, fmt.Errorf("%w: %w", ErrNoFreeSlot, ctx.Err())
+	}
+}
+
+// release makes a slot available again, it must be released exactly once per acquire
+func (a *slotAllocator) release(slot int) {
+	a.free <- slot
+}
+
+// inUse counts the reserved slots, including the ones whose container is still starting
+func (a *slotAllocator) inUse() int {
+	return cap(a.free) - len(a.free)
+}